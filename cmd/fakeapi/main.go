@@ -0,0 +1,46 @@
+// Command fakeapi serves a fake Terraform Cloud/Enterprise API from a dataset, so tfbi can
+// be pointed at it with --api-address for dashboard demos without a real account.
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/nicolaka/tfbi/internal/fakeapi"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/alecthomas/kong"
+)
+
+type CLI struct {
+	Fixture       string `placeholder:"/path/to/fixture.yaml" help:"YAML fixture with the resources to serve (defaults to the bundled demo dataset)."`
+	ListenAddress string `default:"0.0.0.0:8080" help:"Address to listen on for API requests."`
+}
+
+func main() {
+	cli := CLI{}
+	kong.Parse(&cli)
+	logger := log.With(log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr)), "caller", log.DefaultCaller)
+
+	var (
+		dataset *fakeapi.Dataset
+		err     error
+	)
+	if cli.Fixture != "" {
+		dataset, err = fakeapi.LoadDataset(cli.Fixture)
+	} else {
+		dataset, err = fakeapi.Demo()
+	}
+	if err != nil {
+		level.Error(logger).Log("msg", "Error loading dataset", "err", err)
+		os.Exit(1)
+	}
+
+	level.Info(logger).Log("msg", "Listening on address", "address", cli.ListenAddress, "resources", len(dataset.Resources))
+	if err := http.ListenAndServe(cli.ListenAddress, fakeapi.NewServer(dataset)); err != nil {
+		level.Error(logger).Log("msg", "Error starting HTTP server", "err", err)
+		os.Exit(1)
+	}
+}
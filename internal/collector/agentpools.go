@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// agentpools is the Metric subsystem we use.
+	agentpoolsSubsystem = "agentpools"
+)
+
+// agentStatuses are the statuses reported by tf_agentpools_agents, even when no agent has them.
+var agentStatuses = []string{"idle", "busy", "unknown", "errored", "exited"}
+
+// Metric descriptors.
+var (
+	AgentPoolsInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, agentpoolsSubsystem, "info"),
+		"Information about existing agent pools",
+		[]string{"id", "name", "organization", "organization_scoped"}, nil,
+	)
+	AgentPoolsAgents = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, agentpoolsSubsystem, "agents"),
+		"Number of agents in the agent pool by status",
+		[]string{"id", "name", "organization", "status"}, nil,
+	)
+	AgentPoolsWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, agentpoolsSubsystem, "workspaces"),
+		"Number of workspaces assigned to the agent pool",
+		[]string{"id", "name", "organization"}, nil,
+	)
+	AgentPoolsAgentLastPing = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, agentpoolsSubsystem, "agent_last_ping_timestamp_seconds"),
+		"Time the agent of the pool last pinged Terraform Cloud/Enterprise",
+		[]string{"id", "name", "organization", "agent_id", "agent_name", "status"}, nil,
+	)
+)
+
+// ScrapeAgentPools scrapes metrics about the agent pools and their agents.
+type ScrapeAgentPools struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeAgentPools{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeAgentPools) Name() string {
+	return agentpoolsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeAgentPools) Help() string {
+	return "Scrape information from the Agent Pools API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/agents"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeAgentPools) Version() string {
+	return "v2"
+}
+
+func listAgents(ctx context.Context, poolID string, config *setup.Config) ([]*tfe.Agent, error) {
+	var agents []*tfe.Agent
+	for page := 1; ; page++ {
+		agentsList, err := config.Client.Agents.List(ctx, poolID, &tfe.AgentListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (agent_pool=%s, page=%d)", err, poolID, page)
+		}
+
+		agents = append(agents, agentsList.Items...)
+		if agentsList.Pagination == nil || page >= agentsList.Pagination.TotalPages {
+			return agents, nil
+		}
+	}
+}
+
+func getAgentPool(ctx context.Context, p *tfe.AgentPool, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	agents, err := listAgents(ctx, p.ID, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(AgentPoolsInfo, prometheus.GaugeValue, 1, p.ID, p.Name, organization, strconv.FormatBool(p.OrganizationScoped)),
+		prometheus.MustNewConstMetric(AgentPoolsWorkspaces, prometheus.GaugeValue, float64(len(p.Workspaces)), p.ID, p.Name, organization),
+	}
+
+	byStatus := make(map[string]int, len(agentStatuses))
+	for _, a := range agents {
+		byStatus[a.Status]++
+
+		lastPing, err := time.Parse(time.RFC3339, a.LastPingAt)
+		if err != nil {
+			continue
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(AgentPoolsAgentLastPing, prometheus.GaugeValue, float64(lastPing.Unix()), p.ID, p.Name, organization, a.ID, a.Name, a.Status))
+	}
+	for _, status := range agentStatuses {
+		metrics = append(metrics, prometheus.MustNewConstMetric(AgentPoolsAgents, prometheus.GaugeValue, float64(byStatus[status]), p.ID, p.Name, organization, status))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeAgentPools) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			for page := 1; ; page++ {
+				poolsList, err := config.Client.AgentPools.List(ctx, name, &tfe.AgentPoolListOptions{
+					ListOptions: tfe.ListOptions{
+						PageSize:   pageSize,
+						PageNumber: page,
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("%v, (organization=%s, page=%d)", err, name, page)
+				}
+
+				for _, p := range poolsList.Items {
+					if err := getAgentPool(ctx, p, name, config, ch); err != nil {
+						return err
+					}
+				}
+
+				if poolsList.Pagination == nil || page >= poolsList.Pagination.TotalPages {
+					return nil
+				}
+			}
+		})
+	}
+
+	return g.Wait()
+}
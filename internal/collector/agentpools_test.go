@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeAgentPools(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Agents by status", t, func() {
+		got := scrapeMetrics(t, ScrapeAgentPools{}, config, AgentPoolsAgents)
+		convey.So(got, convey.ShouldHaveLength, 10)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "apool-datacenter", "name": "datacenter", "organization": "demo-org", "status": "idle"}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "apool-spare", "name": "spare", "organization": "demo-org", "status": "idle"}, value: 0, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Assigned workspaces", t, func() {
+		got := scrapeMetrics(t, ScrapeAgentPools{}, config, AgentPoolsWorkspaces)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "apool-datacenter", "name": "datacenter", "organization": "demo-org"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Agent last ping time", t, func() {
+		got := scrapeMetrics(t, ScrapeAgentPools{}, config, AgentPoolsAgentLastPing)
+		convey.So(got, convey.ShouldHaveLength, 3)
+		for _, m := range got {
+			convey.So(m.labels["id"], convey.ShouldEqual, "apool-datacenter")
+			convey.So(m.labels["name"], convey.ShouldEqual, "datacenter")
+		}
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "apool-datacenter", "name": "datacenter", "organization": "demo-org", "agent_id": "agent-dc-1", "agent_name": "dc-agent-1", "status": "busy",
+		}, value: float64(time.Date(2024, 9, 2, 10, 0, 0, 0, time.UTC).Unix()), metricType: dto.MetricType_GAUGE})
+	})
+}
@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// assessments is the name of the Scraper, its metrics belong to the workspaces subsystem.
+	assessmentsSubsystem = "assessments"
+)
+
+// Metric descriptors.
+var (
+	WorkspacesDrifted = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "drifted"),
+		"Whether the last health assessment of the workspace detected drift (1 for drifted, 0 otherwise)",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesResourcesDrifted = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "resources_drifted"),
+		"Number of drifted resources found by the last health assessment of the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesChecksFailed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "checks_failed"),
+		"Number of failed continuous validation checks in the last health assessment of the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesChecksUnknown = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "checks_unknown"),
+		"Number of continuous validation checks with an unknown result in the last health assessment of the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesLastAssessment = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "last_assessment_timestamp_seconds"),
+		"Time of the last health assessment of the workspace, in seconds since the Unix epoch",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+)
+
+// ScrapeAssessments scrapes the drift detection and continuous validation results of the workspaces.
+type ScrapeAssessments struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeAssessments{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeAssessments) Name() string {
+	return assessmentsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeAssessments) Help() string {
+	return "Scrape information from the Assessment Results API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/assessment-results"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeAssessments) Version() string {
+	return "v2"
+}
+
+// assessmentResult is the current assessment result of a workspace, which go-tfe doesn't support.
+type assessmentResult struct {
+	ID                 string    `jsonapi:"primary,assessment-results"`
+	CreatedAt          time.Time `jsonapi:"attr,created-at,iso8601"`
+	Drifted            bool      `jsonapi:"attr,drifted"`
+	Succeeded          bool      `jsonapi:"attr,succeeded"`
+	ResourcesDrifted   int       `jsonapi:"attr,resources-drifted"`
+	ResourcesUndrifted int       `jsonapi:"attr,resources-undrifted"`
+	ChecksPassed       int       `jsonapi:"attr,checks-passed"`
+	ChecksFailed       int       `jsonapi:"attr,checks-failed"`
+	ChecksErrored      int       `jsonapi:"attr,checks-errored"`
+	ChecksUnknown      int       `jsonapi:"attr,checks-unknown"`
+}
+
+// readCurrentAssessmentResult returns the current assessment result of the workspace, nil if it was never assessed.
+func readCurrentAssessmentResult(ctx context.Context, workspaceID string, config *setup.Config) (*assessmentResult, error) {
+	req, err := config.Client.NewRequest("GET", fmt.Sprintf("workspaces/%s/current-assessment-result", url.PathEscape(workspaceID)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &assessmentResult{}
+	if err := req.Do(ctx, a); err != nil {
+		if errors.Is(err, tfe.ErrResourceNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%v, workspace=%s", err, workspaceID)
+	}
+
+	return a, nil
+}
+
+func getWorkspaceAssessment(ctx context.Context, w *tfe.Workspace, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	a, err := readCurrentAssessmentResult(ctx, w.ID, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+	if a == nil {
+		return nil
+	}
+
+	project := getProjectName(w)
+	drifted := 0.0
+	if a.Drifted {
+		drifted = 1
+	}
+
+	for _, m := range []prometheus.Metric{
+		prometheus.MustNewConstMetric(WorkspacesDrifted, prometheus.GaugeValue, drifted, w.ID, w.Name, organization, project),
+		prometheus.MustNewConstMetric(WorkspacesResourcesDrifted, prometheus.GaugeValue, float64(a.ResourcesDrifted), w.ID, w.Name, organization, project),
+		prometheus.MustNewConstMetric(WorkspacesChecksFailed, prometheus.GaugeValue, float64(a.ChecksFailed), w.ID, w.Name, organization, project),
+		prometheus.MustNewConstMetric(WorkspacesChecksUnknown, prometheus.GaugeValue, float64(a.ChecksUnknown), w.ID, w.Name, organization, project),
+		prometheus.MustNewConstMetric(WorkspacesLastAssessment, prometheus.GaugeValue, float64(a.CreatedAt.Unix()), w.ID, w.Name, organization, project),
+	} {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeAssessments) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				// Only workspaces with health assessments enabled have results.
+				if !w.AssessmentsEnabled {
+					continue
+				}
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspaceAssessment(wsCtx, w, name, config, ch)
+				})
+			}
+			return wsErrs.Wait()
+		})
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,26 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeAssessments(t *testing.T) {
+	config := newDemoConfig(t)
+	labels := labelMap{"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform"}
+
+	convey.Convey("Only assessed workspaces are reported", t, func() {
+		got := scrapeMetrics(t, ScrapeAssessments{}, config, WorkspacesDrifted)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labels, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Assessment results", t, func() {
+		convey.So(scrapeMetrics(t, ScrapeAssessments{}, config, WorkspacesResourcesDrifted), convey.ShouldContain, MetricResult{labels: labels, value: 3, metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeAssessments{}, config, WorkspacesChecksFailed), convey.ShouldContain, MetricResult{labels: labels, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeAssessments{}, config, WorkspacesLastAssessment), convey.ShouldContain, MetricResult{labels: labels, value: 1725256800, metricType: dto.MetricType_GAUGE})
+	})
+}
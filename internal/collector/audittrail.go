@@ -0,0 +1,205 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// audittrail is the Metric subsystem we use.
+	auditTrailSubsystem = "audittrail"
+)
+
+// Metric descriptors.
+var (
+	AuditTrailEvents = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, auditTrailSubsystem, "events_total"),
+		"Total number of audit trail events read by event type, action, resource type and actor type",
+		[]string{"organization_id", "type", "action", "resource_type", "actor_type"}, nil,
+	)
+	AuditTrailCursor = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, auditTrailSubsystem, "cursor_timestamp_seconds"),
+		"Time of the last audit trail event read, from which the next scrape reads",
+		nil, nil,
+	)
+)
+
+// ScrapeAuditTrail reads the organization audit trail incrementally and counts its events.
+type ScrapeAuditTrail struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeAuditTrail{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeAuditTrail) Name() string {
+	return auditTrailSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeAuditTrail) Help() string {
+	return "Scrape information from the Audit Trails API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/audit-trails"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeAuditTrail) Version() string {
+	return "v2"
+}
+
+type auditTrailKey struct {
+	OrganizationID string `json:"organization_id"`
+	Type           string `json:"type"`
+	Action         string `json:"action"`
+	ResourceType   string `json:"resource_type"`
+	ActorType      string `json:"actor_type"`
+}
+
+type auditTrailCount struct {
+	auditTrailKey
+	Count float64 `json:"count"`
+}
+
+// auditTrailState is the cursor of the audit trail and the counters of the events read so far.
+type auditTrailState struct {
+	Since time.Time `json:"since"`
+	// SeenAtSince holds the ids of the events at Since, which are returned again by the next read.
+	SeenAtSince []string          `json:"seen_at_since"`
+	Events      []auditTrailCount `json:"events"`
+}
+
+func listAuditTrail(ctx context.Context, client *tfe.Client, since time.Time) ([]*tfe.AuditTrail, error) {
+	var events []*tfe.AuditTrail
+	for page := 1; ; page++ {
+		auditTrailList, err := client.AuditTrails.List(ctx, &tfe.AuditTrailListOptions{
+			Since: since,
+			ListOptions: &tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (since=%s, page=%d)", err, since.Format(time.RFC3339), page)
+		}
+
+		events = append(events, auditTrailList.Items...)
+		if auditTrailList.AuditTrailPagination == nil || page >= auditTrailList.TotalPages {
+			return events, nil
+		}
+	}
+}
+
+// read counts the events not read yet and moves the cursor to the last of them.
+func (s *auditTrailState) read(events []*tfe.AuditTrail) {
+	counts := make(map[auditTrailKey]float64, len(s.Events))
+	for _, c := range s.Events {
+		counts[c.auditTrailKey] += c.Count
+	}
+	seen := make(map[string]bool, len(s.SeenAtSince))
+	for _, id := range s.SeenAtSince {
+		seen[id] = true
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	for _, e := range events {
+		// Events before the cursor or already counted are returned again when it didn't move.
+		if e.Timestamp.Before(s.Since) || seen[e.ID] {
+			continue
+		}
+		if e.Timestamp.After(s.Since) {
+			s.Since = e.Timestamp
+			seen = make(map[string]bool)
+		}
+		if e.Timestamp.Equal(s.Since) {
+			seen[e.ID] = true
+		}
+
+		counts[auditTrailKey{
+			OrganizationID: e.Auth.OrganizationID,
+			Type:           e.Type,
+			Action:         e.Resource.Action,
+			ResourceType:   e.Resource.Type,
+			ActorType:      e.Auth.Type,
+		}]++
+	}
+
+	s.SeenAtSince = s.SeenAtSince[:0]
+	for id := range seen {
+		s.SeenAtSince = append(s.SeenAtSince, id)
+	}
+	s.Events = s.Events[:0]
+	for k, count := range counts {
+		s.Events = append(s.Events, auditTrailCount{auditTrailKey: k, Count: count})
+	}
+}
+
+// ScrapeState reads the audit trail from the cursor of the state and sends the counters of all the events read so far.
+func (ScrapeAuditTrail) ScrapeState(ctx context.Context, config *setup.Config, state json.RawMessage, ch chan<- prometheus.Metric) (json.RawMessage, error) {
+	s := &auditTrailState{}
+	if state != nil {
+		if err := json.Unmarshal(state, s); err != nil {
+			level.Warn(config.Logger).Log("msg", "Ignoring invalid audit trail state", "err", err)
+			s = &auditTrailState{}
+		}
+	}
+	if s.Since.IsZero() {
+		s.Since = time.Now().Add(-config.AuditTrailLookback)
+	}
+
+	client := config.AuditTrailClient
+	if client == nil {
+		client = &config.Client
+	}
+	events, err := listAuditTrail(ctx, client, s.Since)
+	if err != nil {
+		return nil, err
+	}
+	// Events read while paging through a list that changed may be returned twice, SeenAtSince doesn't cover them.
+	events = uniqueAuditTrail(events)
+	s.read(events)
+
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(AuditTrailCursor, prometheus.GaugeValue, float64(s.Since.Unix())),
+	}
+	for _, c := range s.Events {
+		metrics = append(metrics, prometheus.MustNewConstMetric(AuditTrailEvents, prometheus.CounterValue, c.Count, c.OrganizationID, c.Type, c.Action, c.ResourceType, c.ActorType))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return json.Marshal(s)
+}
+
+func uniqueAuditTrail(events []*tfe.AuditTrail) []*tfe.AuditTrail {
+	seen := make(map[string]bool, len(events))
+	unique := events[:0]
+	for _, e := range events {
+		if !seen[e.ID] {
+			seen[e.ID] = true
+			unique = append(unique, e)
+		}
+	}
+
+	return unique
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+// Without the state kept by the Exporter, it only counts the events within the lookback window.
+func (s ScrapeAuditTrail) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	_, err := s.ScrapeState(ctx, config, nil, ch)
+	return err
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nicolaka/tfbi/internal/fakeapi"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// scrapeAuditTrail runs the audit trail scraper from the state and returns its events counters and next state.
+func scrapeAuditTrail(t *testing.T, config *setup.Config, state json.RawMessage) ([]MetricResult, json.RawMessage) {
+	t.Helper()
+	ch := make(chan prometheus.Metric)
+	done := make(chan json.RawMessage, 1)
+	go func() {
+		defer close(ch)
+		next, err := ScrapeAuditTrail{}.ScrapeState(context.Background(), config, state, ch)
+		if err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		done <- next
+	}()
+
+	var results []MetricResult
+	for m := range ch {
+		if m.Desc() == AuditTrailEvents {
+			results = append(results, readMetric(m))
+		}
+	}
+	return results, <-done
+}
+
+func TestScrapeAuditTrail(t *testing.T) {
+	dataset, err := fakeapi.Demo()
+	if err != nil {
+		t.Fatalf("error loading demo dataset: %s", err)
+	}
+	config := newFakeConfig(t, dataset)
+	config.AuditTrailLookback = time.Since(time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC))
+
+	workspaceDestroys := func(got []MetricResult) float64 {
+		for _, m := range got {
+			if m.labels["resource_type"] == "workspace" && m.labels["action"] == "destroy" {
+				return m.value
+			}
+		}
+		return 0
+	}
+
+	var state json.RawMessage
+	convey.Convey("Events within the lookback window are counted on the first read", t, func() {
+		var got []MetricResult
+		got, state = scrapeAuditTrail(t, config, nil)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization_id": "org-demo", "type": "Resource", "action": "create", "resource_type": "authentication-token", "actor_type": "Client"}, value: 1, metricType: dto.MetricType_COUNTER})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization_id": "org-demo", "type": "Resource", "action": "update", "resource_type": "workspace", "actor_type": "Impersonated"}, value: 1, metricType: dto.MetricType_COUNTER})
+	})
+
+	convey.Convey("Events at the cursor are not counted twice", t, func() {
+		var got []MetricResult
+		got, state = scrapeAuditTrail(t, config, state)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		for _, m := range got {
+			convey.So(m.value, convey.ShouldEqual, 1)
+		}
+	})
+
+	convey.Convey("New events are added to the counters", t, func() {
+		dataset.Resources = append(dataset.Resources, fakeapi.Resource{
+			Type: "audit-trails",
+			ID:   "ae-workspace-destroy-2",
+			Attributes: map[string]any{
+				"type":      "Resource",
+				"timestamp": "2024-09-02T11:00:00Z",
+				"auth":      map[string]any{"type": "Client", "organization_id": "org-demo"},
+				"resource":  map[string]any{"type": "workspace", "action": "destroy"},
+			},
+		})
+		got, _ := scrapeAuditTrail(t, config, state)
+		convey.So(workspaceDestroys(got), convey.ShouldEqual, 2)
+	})
+}
@@ -3,6 +3,11 @@ package collector
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -22,19 +27,43 @@ const (
 )
 
 // Exporter collects TF metrics. It implements the prometheus.Collector interface.
+// Scraped metrics are kept as a snapshot which is served on every Collect call and
+// replaced by Refresh, either per request or from a background loop started with Run.
 type Exporter struct {
-	ctx      context.Context
 	logger   log.Logger
 	config   setup.Config
 	scrapers []Scraper
 	metrics  Metrics
+
+	// refreshMu serializes refreshes so concurrent requests don't crawl the API twice.
+	// It also guards the organization discovery state below.
+	refreshMu sync.Mutex
+	// discover is set when no organizations were configured and they are listed from the API instead.
+	discover      bool
+	lastDiscovery time.Time
+
+	// mu guards the snapshot and the scraper states below.
+	mu          sync.RWMutex
+	results     map[string]scrapeResult
+	lastRefresh time.Time
+	// states holds the state returned by the last successful run of every StatefulScraper.
+	states map[string]json.RawMessage
+}
+
+// scrapeResult holds the metrics sent by a single scraper during its last complete run.
+type scrapeResult struct {
+	metrics  []prometheus.Metric
+	duration time.Duration
+	// scrapedAt is the time of the last successful run, zero if the scraper never succeeded.
+	scrapedAt time.Time
 }
 
 // Metrics represents exporter metrics which values can be carried between http requests.
 type Metrics struct {
-	TotalScrapes prometheus.Counter
-	ScrapeErrors *prometheus.CounterVec
-	Error        prometheus.Gauge
+	TotalScrapes            prometheus.Counter
+	ScrapeErrors            *prometheus.CounterVec
+	Error                   prometheus.Gauge
+	DiscoveredOrganizations prometheus.Gauge
 }
 
 var (
@@ -46,69 +75,224 @@ var (
 		"Collector time duration.",
 		[]string{"collector"}, nil,
 	)
+	snapshotAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "snapshot_age_seconds"),
+		"Seconds since the served metrics snapshot was last refreshed.",
+		nil, nil,
+	)
 )
 
 // New returns a new Terraform API exporter for the provided Config.
-func New(ctx context.Context, config setup.Config, metrics Metrics) *Exporter {
+func New(config setup.Config, metrics Metrics) *Exporter {
+	var scrapers []Scraper
+	for _, scraper := range Scrapers {
+		if enabled, _ := config.Collector(scraper.Name()); enabled {
+			scrapers = append(scrapers, scraper)
+		} else {
+			level.Info(config.Logger).Log("msg", "Collector disabled", "collector", scraper.Name())
+		}
+	}
+
+	states, err := loadStates(config.StateFile)
+	if err != nil {
+		level.Error(config.Logger).Log("msg", "Error loading the collectors state, starting afresh", "file", config.StateFile, "err", err)
+		states = make(map[string]json.RawMessage)
+	}
+
 	return &Exporter{
-		ctx:      ctx,
 		logger:   config.Logger,
 		config:   config,
-		scrapers: Scrapers,
+		scrapers: scrapers,
 		metrics:  metrics,
+		results:  make(map[string]scrapeResult),
+		states:   states,
+		discover: len(config.Organizations) == 0,
+	}
+}
+
+// loadStates reads the scraper states persisted to the state file, if any.
+func loadStates(path string) (map[string]json.RawMessage, error) {
+	states := make(map[string]json.RawMessage)
+	if path == "" {
+		return states, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return states, json.Unmarshal(b, &states)
+}
+
+// saveStates persists the scraper states to the state file, if any. The caller must hold mu.
+func (e *Exporter) saveStates() error {
+	if e.config.StateFile == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(e.states)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash doesn't leave a truncated state behind.
+	tmp, err := os.CreateTemp(filepath.Dir(e.config.StateFile), filepath.Base(e.config.StateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), e.config.StateFile)
 }
 
 // Describe implements the prometheus.Collector interface.
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.metrics.TotalScrapes.Desc()
 	ch <- e.metrics.Error.Desc()
+	ch <- e.metrics.DiscoveredOrganizations.Desc()
 	e.metrics.ScrapeErrors.Describe(ch)
 }
 
-// Collect implements the prometheus.Collector interface.
+// Collect implements the prometheus.Collector interface. It serves the last snapshot
+// without calling the Terraform API.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
-	e.scrape(e.ctx, ch)
+	e.mu.RLock()
+	for name, result := range e.results {
+		for _, m := range result.metrics {
+			ch <- m
+		}
+		ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, result.duration.Seconds(), "collect."+name)
+	}
+	if !e.lastRefresh.IsZero() {
+		ch <- prometheus.MustNewConstMetric(snapshotAgeDesc, prometheus.GaugeValue, time.Since(e.lastRefresh).Seconds())
+	}
+	e.mu.RUnlock()
 
 	ch <- e.metrics.TotalScrapes
 	ch <- e.metrics.Error
+	if e.discover {
+		ch <- e.metrics.DiscoveredOrganizations
+	}
 	e.metrics.ScrapeErrors.Collect(ch)
 }
 
-func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) {
-	e.metrics.TotalScrapes.Inc()
-	if len(e.config.Organizations) == 0 {
-		// Note: At some point this will return a paginated response.
-		oo, err := e.config.Client.Organizations.List(ctx, nil)
-		if err != nil {
-			e.metrics.Error.Set(1)
-			level.Error(e.logger).Log("msg", "Unable to List Organizations", "err", err)
-			return
-		}
+// Run refreshes the snapshot every interval until ctx is cancelled.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-		for _, o := range oo.Items {
-			e.config.Organizations = append(e.config.Organizations, o.Name)
+	for {
+		e.Refresh(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
 		}
 	}
+}
 
+// Refresh runs all scrapers that are due against the Terraform API and replaces their part of the snapshot.
+// A scraper that fails keeps serving the results of its last complete run.
+func (e *Exporter) Refresh(ctx context.Context) {
+	e.refreshMu.Lock()
+	defer e.refreshMu.Unlock()
+
+	e.metrics.TotalScrapes.Inc()
 	e.metrics.Error.Set(0)
+	if err := e.discoverOrganizations(ctx); err != nil {
+		e.metrics.Error.Set(1)
+		// The organizations found by the last successful discovery, if any, are still scraped.
+		if len(e.config.Organizations) == 0 {
+			return
+		}
+	}
 
+	// The lists and state versions several scrapers need are read once for all the scrapers of the refresh.
+	ctx = withListings(ctx)
 	var wg sync.WaitGroup
-	defer wg.Wait()
 	for _, scraper := range e.scrapers {
+		if !e.due(scraper) {
+			continue
+		}
 		wg.Add(1)
 		go func(scraper Scraper) {
 			defer wg.Done()
-			label := "collect." + scraper.Name()
-			scrapeTime := time.Now()
-			if err := scraper.Scrape(ctx, &e.config, ch); err != nil {
-				level.Error(e.logger).Log("msg", "Error from scraper", "scraper", scraper.Name(), "err", err)
-				e.metrics.ScrapeErrors.WithLabelValues(label).Inc()
-				e.metrics.Error.Set(1)
-			}
-			ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
+			e.runScraper(ctx, scraper)
 		}(scraper)
 	}
+	wg.Wait()
+
+	e.mu.Lock()
+	e.lastRefresh = time.Now()
+	e.mu.Unlock()
+}
+
+// due reports whether the scraper's refresh interval has elapsed since its last successful run.
+func (e *Exporter) due(scraper Scraper) bool {
+	_, interval := e.config.Collector(scraper.Name())
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return time.Since(e.results[scraper.Name()].scrapedAt) >= interval
+}
+
+func (e *Exporter) runScraper(ctx context.Context, scraper Scraper) {
+	label := "collect." + scraper.Name()
+	scrapeTime := time.Now()
+
+	ch := make(chan prometheus.Metric)
+	done := make(chan []prometheus.Metric)
+	go func() {
+		var metrics []prometheus.Metric
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		done <- metrics
+	}()
+
+	var err error
+	stateful, isStateful := scraper.(StatefulScraper)
+	var state json.RawMessage
+	if isStateful {
+		e.mu.RLock()
+		state = e.states[scraper.Name()]
+		e.mu.RUnlock()
+		state, err = stateful.ScrapeState(ctx, &e.config, state, ch)
+	} else {
+		err = scraper.Scrape(ctx, &e.config, ch)
+	}
+	close(ch)
+	metrics := <-done
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		level.Error(e.logger).Log("msg", "Error from scraper", "scraper", scraper.Name(), "err", err)
+		e.metrics.ScrapeErrors.WithLabelValues(label).Inc()
+		e.metrics.Error.Set(1)
+		if _, ok := e.results[scraper.Name()]; !ok {
+			e.results[scraper.Name()] = scrapeResult{metrics: metrics, duration: time.Since(scrapeTime)}
+		}
+		return
+	}
+	e.results[scraper.Name()] = scrapeResult{metrics: metrics, duration: time.Since(scrapeTime), scrapedAt: time.Now()}
+	if isStateful {
+		e.states[scraper.Name()] = state
+		if err := e.saveStates(); err != nil {
+			level.Error(e.logger).Log("msg", "Error saving the collectors state", "file", e.config.StateFile, "err", err)
+		}
+	}
 }
 
 // NewMetrics creates new Metrics instance.
@@ -132,5 +316,11 @@ func NewMetrics() Metrics {
 			Name:      "last_scrape_error",
 			Help:      "Whether the last scrape of metrics from Terraform API resulted in an error (1 for error, 0 for success).",
 		}),
+		DiscoveredOrganizations: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: exporter,
+			Name:      "discovered_organizations",
+			Help:      "Number of organizations found by the last organization discovery.",
+		}),
 	}
 }
@@ -1,8 +1,25 @@
 package collector
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nicolaka/tfbi/internal/fakeapi"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/go-kit/kit/log"
+
+	tfe "github.com/hashicorp/go-tfe"
+
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
 )
 
 type labelMap map[string]string
@@ -26,8 +43,193 @@ func readMetric(m prometheus.Metric) MetricResult {
 	if pb.Counter != nil {
 		return MetricResult{labels: labels, value: pb.GetCounter().GetValue(), metricType: dto.MetricType_COUNTER}
 	}
+	if pb.Histogram != nil {
+		return MetricResult{labels: labels, value: float64(pb.GetHistogram().GetSampleCount()), metricType: dto.MetricType_HISTOGRAM}
+	}
 	if pb.Untyped != nil {
 		return MetricResult{labels: labels, value: pb.GetUntyped().GetValue(), metricType: dto.MetricType_UNTYPED}
 	}
 	panic("Unsupported metric type")
 }
+
+type stubScraper struct {
+	name string
+	err  error
+}
+
+func (s stubScraper) Name() string    { return s.name }
+func (s stubScraper) Help() string    { return "Stub scraper" }
+func (s stubScraper) Version() string { return "v2" }
+
+func (s stubScraper) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	ch <- prometheus.MustNewConstMetric(OrganizationsInfo, prometheus.GaugeValue, 1, s.name, "", "", "", "", "", "", "")
+	return s.err
+}
+
+// stubStatefulScraper counts its runs in its state.
+type stubStatefulScraper struct {
+	stubScraper
+}
+
+func (s stubStatefulScraper) ScrapeState(ctx context.Context, config *setup.Config, state json.RawMessage, ch chan<- prometheus.Metric) (json.RawMessage, error) {
+	var runs int
+	if state != nil {
+		if err := json.Unmarshal(state, &runs); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Scrape(ctx, config, ch); err != nil {
+		return nil, err
+	}
+	return json.Marshal(runs + 1)
+}
+
+func collectAll(e *Exporter) []prometheus.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		e.Collect(ch)
+	}()
+
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestExporterSnapshot(t *testing.T) {
+	config := setup.Config{
+		CLI:    setup.CLI{Organizations: []string{"test-org"}},
+		Logger: log.NewNopLogger(),
+	}
+	e := New(config, NewMetrics())
+
+	convey.Convey("Snapshot is empty before the first refresh", t, func() {
+		e.scrapers = []Scraper{stubScraper{name: "stub"}}
+		convey.So(collectAll(e), convey.ShouldHaveLength, 2)
+	})
+
+	convey.Convey("Snapshot is served after a refresh", t, func() {
+		e.Refresh(context.Background())
+		// stub metric, duration, snapshot age, total scrapes and last scrape error.
+		convey.So(collectAll(e), convey.ShouldHaveLength, 5)
+	})
+
+	convey.Convey("Failing scraper keeps its last complete result", t, func() {
+		e.scrapers = []Scraper{stubScraper{name: "stub", err: errors.New("boom")}}
+		e.Refresh(context.Background())
+		// Same as above plus the scrape errors counter.
+		convey.So(collectAll(e), convey.ShouldHaveLength, 6)
+		convey.So(readMetric(e.metrics.Error).value, convey.ShouldEqual, 1)
+	})
+}
+
+func TestExporterCollectorSettings(t *testing.T) {
+	config := setup.Config{
+		CLI: setup.CLI{
+			Organizations: []string{"test-org"},
+			Collectors: setup.Collectors{
+				Teams:         true,
+				TeamsInterval: time.Hour,
+			},
+		},
+		Logger: log.NewNopLogger(),
+	}
+	e := New(config, NewMetrics())
+
+	convey.Convey("Disabled collectors are not scraped", t, func() {
+		for _, scraper := range e.scrapers {
+			convey.So(scraper.Name(), convey.ShouldEqual, "teams")
+		}
+	})
+
+	convey.Convey("Collectors are not refreshed before their interval elapsed", t, func() {
+		e.scrapers = []Scraper{stubScraper{name: "teams"}}
+		e.Refresh(context.Background())
+		scrapedAt := e.results["teams"].scrapedAt
+		e.Refresh(context.Background())
+		convey.So(e.results["teams"].scrapedAt, convey.ShouldEqual, scrapedAt)
+	})
+}
+
+func TestExporterState(t *testing.T) {
+	config := setup.Config{
+		CLI: setup.CLI{
+			Organizations: []string{"test-org"},
+			StateFile:     filepath.Join(t.TempDir(), "state.json"),
+		},
+		Logger: log.NewNopLogger(),
+	}
+
+	convey.Convey("State is passed to the next run of the scraper", t, func() {
+		e := New(config, NewMetrics())
+		e.scrapers = []Scraper{stubStatefulScraper{stubScraper{name: "stateful"}}}
+		e.Refresh(context.Background())
+		e.Refresh(context.Background())
+		convey.So(string(e.states["stateful"]), convey.ShouldEqual, "2")
+	})
+
+	convey.Convey("State of a failed run is discarded", t, func() {
+		e := New(config, NewMetrics())
+		e.scrapers = []Scraper{stubStatefulScraper{stubScraper{name: "stateful", err: errors.New("boom")}}}
+		e.Refresh(context.Background())
+		convey.So(string(e.states["stateful"]), convey.ShouldEqual, "2")
+	})
+
+	convey.Convey("State is persisted across restarts", t, func() {
+		e := New(config, NewMetrics())
+		e.scrapers = []Scraper{stubStatefulScraper{stubScraper{name: "stateful"}}}
+		e.Refresh(context.Background())
+		convey.So(string(e.states["stateful"]), convey.ShouldEqual, "3")
+	})
+}
+
+// newFakeConfig returns a Config whose client talks to a fake API serving the dataset.
+func newFakeConfig(t *testing.T, dataset *fakeapi.Dataset, organizations ...string) *setup.Config {
+	t.Helper()
+	server := httptest.NewServer(fakeapi.NewServer(dataset))
+	t.Cleanup(server.Close)
+
+	client, err := tfe.NewClient(&tfe.Config{Address: server.URL, Token: "test"})
+	if err != nil {
+		t.Fatalf("error creating a stub api client: %s", err)
+	}
+
+	return &setup.Config{
+		Client: *client,
+		CLI:    setup.CLI{Organizations: organizations},
+		Logger: log.NewNopLogger(),
+	}
+}
+
+// newDemoConfig returns a Config whose client talks to a fake API serving the demo dataset.
+func newDemoConfig(t *testing.T) *setup.Config {
+	t.Helper()
+	dataset, err := fakeapi.Demo()
+	if err != nil {
+		t.Fatalf("error loading demo dataset: %s", err)
+	}
+
+	return newFakeConfig(t, dataset, "demo-org")
+}
+
+// scrapeMetrics runs the scraper and returns the metrics it sent for the given descriptor.
+func scrapeMetrics(t *testing.T, scraper Scraper, config *setup.Config, desc *prometheus.Desc) []MetricResult {
+	t.Helper()
+	ch := make(chan prometheus.Metric)
+	go func() {
+		defer close(ch)
+		if err := scraper.Scrape(context.Background(), config, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+	}()
+
+	var results []MetricResult
+	for m := range ch {
+		if m.Desc() == desc {
+			results = append(results, readMetric(m))
+		}
+	}
+	return results
+}
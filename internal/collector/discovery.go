@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+
+	"github.com/go-kit/kit/log/level"
+)
+
+// discoverOrganizations refreshes the list of organizations to scrape when none were configured.
+// All pages are walked and the names are filtered with the configured include/exclude patterns.
+// If the organizations can't be listed, the error is returned and those of the last successful discovery are kept.
+func (e *Exporter) discoverOrganizations(ctx context.Context) error {
+	if !e.discover {
+		return nil
+	}
+
+	if !e.lastDiscovery.IsZero() && time.Since(e.lastDiscovery) < e.config.OrganizationsRefreshInterval {
+		return nil
+	}
+
+	var organizations []string
+	for page := 1; ; page++ {
+		oo, err := e.config.Client.Organizations.List(ctx, &tfe.OrganizationListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			level.Error(e.logger).Log("msg", "Unable to List Organizations", "page", page, "err", err)
+			return err
+		}
+
+		for _, o := range oo.Items {
+			if e.config.MatchOrganization(o.Name) {
+				organizations = append(organizations, o.Name)
+			}
+		}
+
+		if oo.Pagination == nil || page >= oo.Pagination.TotalPages {
+			break
+		}
+	}
+
+	level.Debug(e.logger).Log("msg", "Discovered organizations", "organizations", len(organizations))
+	e.config.Organizations = organizations
+	e.lastDiscovery = time.Now()
+	e.metrics.DiscoveredOrganizations.Set(float64(len(organizations)))
+	return nil
+}
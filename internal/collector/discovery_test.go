@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicolaka/tfbi/internal/fakeapi"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/go-kit/kit/log"
+
+	tfe "github.com/hashicorp/go-tfe"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiscoverOrganizations(t *testing.T) {
+	dataset := &fakeapi.Dataset{}
+	for i := 0; i < pageSize+5; i++ {
+		dataset.Resources = append(dataset.Resources, fakeapi.Resource{
+			Type:       "organizations",
+			ID:         fmt.Sprintf("org-%d", i),
+			Attributes: map[string]any{"name": fmt.Sprintf("org-%d", i)},
+		})
+	}
+	config := newFakeConfig(t, dataset)
+	config.OrganizationsRefreshInterval = time.Hour
+	e := New(*config, NewMetrics())
+
+	convey.Convey("All pages are discovered", t, func() {
+		convey.So(e.discoverOrganizations(context.Background()), convey.ShouldBeNil)
+		convey.So(e.config.Organizations, convey.ShouldHaveLength, pageSize+5)
+		convey.So(readMetric(e.metrics.DiscoveredOrganizations).value, convey.ShouldEqual, pageSize+5)
+	})
+
+	convey.Convey("Organizations are not listed again before the refresh interval", t, func() {
+		dataset.Resources = dataset.Resources[:1]
+		e.discoverOrganizations(context.Background())
+		convey.So(e.config.Organizations, convey.ShouldHaveLength, pageSize+5)
+	})
+
+	convey.Convey("Organizations are replaced once the refresh interval elapsed", t, func() {
+		e.lastDiscovery = time.Now().Add(-2 * time.Hour)
+		e.discoverOrganizations(context.Background())
+		convey.So(e.config.Organizations, convey.ShouldResemble, []string{"org-0"})
+	})
+}
+
+func TestDiscoverOrganizationsFailure(t *testing.T) {
+	dataset := &fakeapi.Dataset{Resources: []fakeapi.Resource{
+		{Type: "organizations", ID: "org-0", Attributes: map[string]any{"name": "org-0"}},
+	}}
+	server := httptest.NewServer(fakeapi.NewServer(dataset))
+	defer server.Close()
+	client, err := tfe.NewClient(&tfe.Config{Address: server.URL, Token: "test"})
+	if err != nil {
+		t.Fatalf("error creating a stub api client: %s", err)
+	}
+	e := New(setup.Config{Client: *client, Logger: log.NewNopLogger()}, NewMetrics())
+	e.scrapers = nil
+
+	convey.Convey("Organizations are discovered", t, func() {
+		e.Refresh(context.Background())
+		convey.So(e.config.Organizations, convey.ShouldResemble, []string{"org-0"})
+		convey.So(readMetric(e.metrics.Error).value, convey.ShouldEqual, 0)
+	})
+
+	convey.Convey("The last organizations are still scraped when the discovery fails, with the error reported", t, func() {
+		server.Close()
+		e.lastDiscovery = time.Now().Add(-2 * e.config.OrganizationsRefreshInterval)
+		e.scrapers = []Scraper{stubScraper{name: "stub"}}
+		e.Refresh(context.Background())
+		convey.So(e.config.Organizations, convey.ShouldResemble, []string{"org-0"})
+		convey.So(readMetric(e.metrics.Error).value, convey.ShouldEqual, 1)
+
+		var scraped bool
+		for _, m := range collectAll(e) {
+			scraped = scraped || m.Desc() == OrganizationsInfo
+		}
+		convey.So(scraped, convey.ShouldBeTrue)
+	})
+}
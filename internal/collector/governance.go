@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// governance is the Metric subsystem we use.
+	governanceSubsystem = "governance"
+)
+
+// Governance gaps of a workspace.
+const (
+	gapNoPolicySet                 = "no_policy_set"
+	gapNoVCS                       = "no_vcs"
+	gapNoAssessments               = "no_assessments"
+	gapUnsupportedTerraformVersion = "unsupported_terraform_version"
+	gapInactive                    = "inactive"
+)
+
+// governanceGaps are reported by tf_governance_gap_workspaces, even when no workspace has them.
+var governanceGaps = []string{gapNoPolicySet, gapNoVCS, gapNoAssessments, gapUnsupportedTerraformVersion, gapInactive}
+
+// Metric descriptors.
+var (
+	GovernanceGap = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, governanceSubsystem, "gap"),
+		"Governance gaps of the workspace: no_policy_set, no_vcs, no_assessments, unsupported_terraform_version or inactive",
+		[]string{"id", "name", "organization", "project", "gap"}, nil,
+	)
+	GovernanceGapWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, governanceSubsystem, "gap_workspaces"),
+		"Number of workspaces with the governance gap",
+		[]string{"organization", "gap"}, nil,
+	)
+)
+
+// ScrapeGovernance derives the governance gaps of the workspaces from the workspaces and policy sets
+// listed by the other scrapers of the refresh.
+type ScrapeGovernance struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeGovernance{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeGovernance) Name() string {
+	return governanceSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeGovernance) Help() string {
+	return "Derive governance gaps from the Workspaces and Policy Sets APIs"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeGovernance) Version() string {
+	return "v2"
+}
+
+// policyCoverage tells which workspaces are covered by at least one policy set.
+type policyCoverage struct {
+	policySets []*tfe.PolicySet
+}
+
+func (c policyCoverage) covers(w *tfe.Workspace) bool {
+	for _, s := range c.policySets {
+		if containsWorkspace(s.WorkspaceExclusions, w.ID) {
+			continue
+		}
+		if s.Global || containsWorkspace(s.Workspaces, w.ID) {
+			return true
+		}
+		if w.Project != nil && containsProject(s.Projects, w.Project.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsWorkspace(workspaces []*tfe.Workspace, id string) bool {
+	for _, w := range workspaces {
+		if w != nil && w.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsProject(projects []*tfe.Project, id string) bool {
+	for _, p := range projects {
+		if p != nil && p.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inactive returns whether the workspace had no run since the given time, or no run at all.
+func inactive(w *tfe.Workspace, since time.Time) bool {
+	if w.CurrentRun == nil {
+		return true
+	}
+
+	return w.CurrentRun.CreatedAt.Before(since)
+}
+
+// getWorkspaceGaps returns the governance gaps of the workspace. The Terraform version is unsupported by the
+// definition of tf_workspaces_terraform_unsupported, and isn't checked without the available versions.
+func getWorkspaceGaps(w *tfe.Workspace, coverage policyCoverage, tv *terraformVersions, since time.Time, config *setup.Config) []string {
+	var gaps []string
+	if !coverage.covers(w) {
+		gaps = append(gaps, gapNoPolicySet)
+	}
+	if w.VCSRepo == nil {
+		gaps = append(gaps, gapNoVCS)
+	}
+	if !w.AssessmentsEnabled {
+		gaps = append(gaps, gapNoAssessments)
+	}
+	if tv != nil {
+		if v := tv.workspaceVersion(w); v != nil && tv.unsupported(v, config.TerraformVersionsSupportedMinors) {
+			gaps = append(gaps, gapUnsupportedTerraformVersion)
+		}
+	}
+	if inactive(w, since) {
+		gaps = append(gaps, gapInactive)
+	}
+
+	return gaps
+}
+
+func getGovernanceGaps(ctx context.Context, organization string, tv *terraformVersions, config *setup.Config, ch chan<- prometheus.Metric) error {
+	workspaces, err := listWorkspaces(ctx, organization, config)
+	if err != nil {
+		return err
+	}
+	policySets, err := listPolicySets(ctx, organization, config)
+	if err != nil {
+		return err
+	}
+	coverage := policyCoverage{policySets: policySets}
+
+	since := time.Now().Add(-config.GovernanceInactivity)
+	var metrics []prometheus.Metric
+	counts := make(map[string]int, len(governanceGaps))
+	for _, w := range workspaces {
+		for _, gap := range getWorkspaceGaps(w, coverage, tv, since, config) {
+			counts[gap]++
+			metrics = append(metrics, prometheus.MustNewConstMetric(GovernanceGap, prometheus.GaugeValue, 1, w.ID, w.Name, organization, getProjectName(w), gap))
+		}
+	}
+	for _, gap := range governanceGaps {
+		metrics = append(metrics, prometheus.MustNewConstMetric(GovernanceGapWorkspaces, prometheus.GaugeValue, float64(counts[gap]), organization, gap))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeGovernance) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	tv, err := getTerraformVersions(ctx, config)
+	if err != nil {
+		// The other gaps don't need the available versions.
+		level.Warn(config.Logger).Log("msg", "Unable to get the Terraform versions, skipping the unsupported_terraform_version gap", "err", err)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			return getGovernanceGaps(ctx, name, tv, config, ch)
+		})
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeGovernance(t *testing.T) {
+	config := newDemoConfig(t)
+	config.TerraformVersionsSupportedMinors = 3
+	config.TerraformVersionsFile = filepath.Join(t.TempDir(), "versions.json")
+	if err := os.WriteFile(config.TerraformVersionsFile, []byte(testTerraformVersions), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Only network-prod had a run after this time in the demo dataset.
+	config.GovernanceInactivity = time.Since(time.Date(2024, 9, 2, 9, 30, 0, 0, time.UTC))
+
+	convey.Convey("Workspaces with governance gaps", t, func() {
+		got := scrapeMetrics(t, ScrapeGovernance{}, config, GovernanceGap)
+		gaps := make(map[string][]string)
+		for _, m := range got {
+			gaps[m.labels["name"]] = append(gaps[m.labels["name"]], m.labels["gap"])
+		}
+		convey.So(gaps["network-prod"], convey.ShouldBeEmpty)
+		convey.So(gaps["network-dev"], convey.ShouldResemble, []string{"no_vcs", "no_assessments", "inactive"})
+		convey.So(gaps["app-frontend"], convey.ShouldResemble, []string{"unsupported_terraform_version", "inactive"})
+		convey.So(gaps["sandbox"], convey.ShouldResemble, []string{"no_policy_set", "no_vcs", "no_assessments", "unsupported_terraform_version", "inactive"})
+	})
+
+	convey.Convey("Workspaces are counted for every gap", t, func() {
+		got := scrapeMetrics(t, ScrapeGovernance{}, config, GovernanceGapWorkspaces)
+		convey.So(got, convey.ShouldHaveLength, 5)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "gap": "no_policy_set"}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "gap": "inactive"}, value: 3, metricType: dto.MetricType_GAUGE})
+	})
+	convey.Convey("The unsupported Terraform version gap is skipped without the available versions", t, func() {
+		config := *config
+		config.TerraformVersionsFile = filepath.Join(t.TempDir(), "missing.json")
+		got := scrapeMetrics(t, ScrapeGovernance{}, &config, GovernanceGap)
+		gaps := make(map[string][]string)
+		for _, m := range got {
+			gaps[m.labels["name"]] = append(gaps[m.labels["name"]], m.labels["gap"])
+		}
+		convey.So(gaps["app-frontend"], convey.ShouldResemble, []string{"inactive"})
+		convey.So(gaps["sandbox"], convey.ShouldResemble, []string{"no_policy_set", "no_vcs", "no_assessments", "inactive"})
+	})
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"context"
+	"sync"
+)
+
+// listings shares the lists several scrapers need, such as the workspaces and policy sets of an organization,
+// between the scrapers of a refresh, so that they are crawled once per refresh rather than once per scraper.
+type listings struct {
+	// ctx is the context of the refresh. Lists are fetched with it rather than with the context of the
+	// scraper asking first, so that a failing scraper doesn't fail the others waiting for the same list.
+	ctx context.Context
+
+	mu      sync.Mutex
+	entries map[string]*listing
+}
+
+// listing is a list being fetched, done is closed once value and err are set.
+type listing struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+type listingsKey struct{}
+
+// withListings returns a context sharing lists between the scrapers it is passed to.
+func withListings(ctx context.Context) context.Context {
+	l := &listings{entries: make(map[string]*listing)}
+	ctx = context.WithValue(ctx, listingsKey{}, l)
+	l.ctx = ctx
+
+	return ctx
+}
+
+// sharedListing returns the list of the given key, fetching it only if no scraper of the refresh did already.
+// Without listings in the context, as when a scraper runs on its own, the list is fetched every time.
+func sharedListing[T any](ctx context.Context, key string, fetch func(context.Context) (T, error)) (T, error) {
+	l, ok := ctx.Value(listingsKey{}).(*listings)
+	if !ok {
+		return fetch(ctx)
+	}
+
+	l.mu.Lock()
+	e, ok := l.entries[key]
+	if !ok {
+		e = &listing{done: make(chan struct{})}
+		l.entries[key] = e
+		go func() {
+			defer close(e.done)
+			e.value, e.err = fetch(l.ctx)
+		}()
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-e.done:
+		if e.err != nil {
+			var zero T
+			return zero, e.err
+		}
+		return e.value.(T), nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
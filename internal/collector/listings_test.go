@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestSharedListing(t *testing.T) {
+	var fetches atomic.Int32
+	fetch := func(context.Context) ([]string, error) {
+		fetches.Add(1)
+		return []string{"ws-1", "ws-2"}, nil
+	}
+
+	convey.Convey("Lists are fetched once per refresh", t, func() {
+		fetches.Store(0)
+		ctx := withListings(context.Background())
+
+		got := make([][]string, 10)
+		errs := make([]error, 10)
+		var wg sync.WaitGroup
+		for i := range got {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				got[i], errs[i] = sharedListing(ctx, "workspaces/demo-org", fetch)
+			}(i)
+		}
+		wg.Wait()
+		for i := range got {
+			convey.So(errs[i], convey.ShouldBeNil)
+			convey.So(got[i], convey.ShouldResemble, []string{"ws-1", "ws-2"})
+		}
+		convey.So(fetches.Load(), convey.ShouldEqual, 1)
+
+		_, err := sharedListing(ctx, "workspaces/other-org", fetch)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(fetches.Load(), convey.ShouldEqual, 2)
+	})
+
+	convey.Convey("Lists are fetched every time without listings", t, func() {
+		fetches.Store(0)
+		for i := 0; i < 2; i++ {
+			_, err := sharedListing(context.Background(), "workspaces/demo-org", fetch)
+			convey.So(err, convey.ShouldBeNil)
+		}
+		convey.So(fetches.Load(), convey.ShouldEqual, 2)
+	})
+
+	convey.Convey("Errors are shared too", t, func() {
+		ctx := withListings(context.Background())
+		_, err := sharedListing(ctx, "policysets/demo-org", func(context.Context) ([]string, error) {
+			return nil, errors.New("unavailable")
+		})
+		convey.So(err, convey.ShouldNotBeNil)
+
+		_, err = sharedListing(ctx, "policysets/demo-org", fetch)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+	convey.Convey("Waiting is cancelled with the scraper's context", t, func() {
+		ctx := withListings(context.Background())
+		release := make(chan struct{})
+		defer close(release)
+		go sharedListing(ctx, "policysets/demo-org", func(context.Context) ([]string, error) {
+			<-release
+			return nil, nil
+		})
+
+		scraperCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := sharedListing(scraperCtx, "policysets/demo-org", fetch)
+		convey.So(err, convey.ShouldEqual, context.Canceled)
+	})
+}
@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// memberships is the Metric subsystem we use.
+	membershipsSubsystem = "memberships"
+)
+
+// Metric descriptors.
+var (
+	MembershipsMembers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, membershipsSubsystem, "members"),
+		"Number of organization members by membership status, two-factor authentication and service account flag",
+		[]string{"organization", "status", "two_factor", "service_account"}, nil,
+	)
+	MembershipsMembersByTeams = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, membershipsSubsystem, "members_by_teams"),
+		"Number of organization members by the number of teams they belong to",
+		[]string{"organization", "teams"}, nil,
+	)
+)
+
+// ScrapeOrganizationMemberships scrapes metrics about the members of the organizations.
+type ScrapeOrganizationMemberships struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeOrganizationMemberships{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeOrganizationMemberships) Name() string {
+	return membershipsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeOrganizationMemberships) Help() string {
+	return "Scrape information from the Organization Memberships API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/organization-memberships"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeOrganizationMemberships) Version() string {
+	return "v2"
+}
+
+// memberKey identifies a tf_memberships_members series of an organization.
+type memberKey struct {
+	status                    tfe.OrganizationMembershipStatus
+	twoFactor, serviceAccount bool
+}
+
+// memberCounts holds the membership counts of an organization.
+type memberCounts struct {
+	members map[memberKey]int
+	byTeams map[int]int
+}
+
+func (c *memberCounts) add(m *tfe.OrganizationMembership) {
+	if c.members == nil {
+		c.members = make(map[memberKey]int)
+		c.byTeams = make(map[int]int)
+	}
+
+	k := memberKey{status: m.Status}
+	if m.User != nil {
+		k.twoFactor = m.User.TwoFactor != nil && m.User.TwoFactor.Enabled
+		k.serviceAccount = m.User.IsServiceAccount
+	}
+	c.members[k]++
+	c.byTeams[len(m.Teams)]++
+}
+
+func getOrganizationMemberships(ctx context.Context, organization string, config *setup.Config) (*memberCounts, error) {
+	counts := &memberCounts{}
+	for page := 1; ; page++ {
+		membershipsList, err := config.Client.OrganizationMemberships.List(ctx, organization, &tfe.OrganizationMembershipListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			Include: []tfe.OrgMembershipIncludeOpt{tfe.OrgMembershipUser},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+		}
+
+		for _, m := range membershipsList.Items {
+			counts.add(m)
+		}
+
+		if membershipsList.Pagination == nil || page >= membershipsList.Pagination.TotalPages {
+			return counts, nil
+		}
+	}
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeOrganizationMemberships) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			counts, err := getOrganizationMemberships(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			var metrics []prometheus.Metric
+			for k, count := range counts.members {
+				metrics = append(metrics, prometheus.MustNewConstMetric(
+					MembershipsMembers,
+					prometheus.GaugeValue,
+					float64(count),
+					name,
+					string(k.status),
+					strconv.FormatBool(k.twoFactor),
+					strconv.FormatBool(k.serviceAccount),
+				))
+			}
+			for teams, count := range counts.byTeams {
+				metrics = append(metrics, prometheus.MustNewConstMetric(MembershipsMembersByTeams, prometheus.GaugeValue, float64(count), name, strconv.Itoa(teams)))
+			}
+
+			for _, m := range metrics {
+				select {
+				case ch <- m:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
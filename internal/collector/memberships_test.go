@@ -0,0 +1,28 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeOrganizationMemberships(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Members are counted once regardless of their teams", t, func() {
+		got := scrapeMetrics(t, ScrapeOrganizationMemberships{}, config, MembershipsMembers)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "status": "active", "two_factor": "true", "service_account": "false"}, value: 2, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "status": "active", "two_factor": "false", "service_account": "true"}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "status": "invited", "two_factor": "false", "service_account": "false"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Members by number of teams", t, func() {
+		got := scrapeMetrics(t, ScrapeOrganizationMemberships{}, config, MembershipsMembersByTeams)
+		convey.So(got, convey.ShouldHaveLength, 3)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "teams": "2"}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "teams": "0"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+}
@@ -0,0 +1,209 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// notifications is the Metric subsystem we use.
+	notificationsSubsystem = "notifications"
+)
+
+// Metric descriptors.
+var (
+	NotificationsConfigurations = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, notificationsSubsystem, "configurations"),
+		"Number of notification configurations of the workspace by destination type, triggers and enabled flag",
+		[]string{"id", "name", "organization", "project", "destination_type", "triggers", "enabled"}, nil,
+	)
+	NotificationsLastDeliverySuccessful = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, notificationsSubsystem, "last_delivery_successful"),
+		"Whether the last delivery of the notification configuration succeeded (1 for success, 0 otherwise), with its response code",
+		[]string{"id", "name", "organization", "project", "notification_id", "notification_name", "destination_type", "enabled", "code"}, nil,
+	)
+	NotificationsLastDeliveryTimestamp = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, notificationsSubsystem, "last_delivery_timestamp_seconds"),
+		"Time of the last delivery of the notification configuration",
+		[]string{"id", "name", "organization", "project", "notification_id", "notification_name", "destination_type", "enabled"}, nil,
+	)
+)
+
+// ScrapeNotifications scrapes the notification configurations of the workspaces and the health of their deliveries.
+type ScrapeNotifications struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeNotifications{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeNotifications) Name() string {
+	return notificationsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeNotifications) Help() string {
+	return "Scrape information from the Notification Configurations API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/notification-configurations"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeNotifications) Version() string {
+	return "v2"
+}
+
+// notificationConfiguration is a notification configuration of a workspace. go-tfe drops the delivery responses
+// carrying headers, which the API always sends, so they are decoded without them.
+type notificationConfiguration struct {
+	ID                string                          `jsonapi:"primary,notification-configurations"`
+	Name              string                          `jsonapi:"attr,name"`
+	DestinationType   tfe.NotificationDestinationType `jsonapi:"attr,destination-type"`
+	Enabled           bool                            `jsonapi:"attr,enabled"`
+	Triggers          []string                        `jsonapi:"attr,triggers"`
+	DeliveryResponses []*deliveryResponse             `jsonapi:"attr,delivery-responses"`
+}
+
+type deliveryResponse struct {
+	Code       string    `jsonapi:"attr,code"`
+	SentAt     time.Time `jsonapi:"attr,sent-at,rfc3339"`
+	Successful string    `jsonapi:"attr,successful"`
+}
+
+type notificationConfigurationList struct {
+	*tfe.Pagination
+	Items []*notificationConfiguration
+}
+
+// listNotificationConfigurations returns the notification configurations of the workspace.
+func listNotificationConfigurations(ctx context.Context, workspaceID string, config *setup.Config) ([]*notificationConfiguration, error) {
+	var notifications []*notificationConfiguration
+	for page := 1; ; page++ {
+		req, err := config.Client.NewRequest("GET", fmt.Sprintf("workspaces/%s/notification-configurations", url.PathEscape(workspaceID)), &tfe.ListOptions{
+			PageSize:   pageSize,
+			PageNumber: page,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		nl := &notificationConfigurationList{}
+		if err := req.Do(ctx, nl); err != nil {
+			return nil, fmt.Errorf("%v, (workspace=%s, page=%d)", err, workspaceID, page)
+		}
+
+		notifications = append(notifications, nl.Items...)
+		if nl.Pagination == nil || page >= nl.Pagination.TotalPages {
+			return notifications, nil
+		}
+	}
+}
+
+// notificationCountKey identifies a tf_notifications_configurations series of a workspace.
+type notificationCountKey struct {
+	destinationType tfe.NotificationDestinationType
+	triggers        string
+	enabled         bool
+}
+
+// getNotificationTriggers returns the triggers of the notification configuration sorted and comma separated,
+// so that configurations with the same triggers share a series.
+func getNotificationTriggers(n *notificationConfiguration) string {
+	triggers := append([]string(nil), n.Triggers...)
+	sort.Strings(triggers)
+
+	return strings.Join(triggers, ",")
+}
+
+// getLastDelivery returns the most recent delivery response of the notification configuration, nil if it never sent one.
+func getLastDelivery(n *notificationConfiguration) *deliveryResponse {
+	var last *deliveryResponse
+	for _, d := range n.DeliveryResponses {
+		if d != nil && (last == nil || d.SentAt.After(last.SentAt)) {
+			last = d
+		}
+	}
+
+	return last
+}
+
+func getWorkspaceNotifications(ctx context.Context, w *tfe.Workspace, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	notifications, err := listNotificationConfigurations(ctx, w.ID, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+
+	project := getProjectName(w)
+	counts := make(map[notificationCountKey]int)
+	var metrics []prometheus.Metric
+	for _, n := range notifications {
+		counts[notificationCountKey{destinationType: n.DestinationType, triggers: getNotificationTriggers(n), enabled: n.Enabled}]++
+
+		last := getLastDelivery(n)
+		if last == nil {
+			continue
+		}
+		successful := 0.0
+		if ok, _ := strconv.ParseBool(last.Successful); ok {
+			successful = 1
+		}
+		enabled := strconv.FormatBool(n.Enabled)
+		metrics = append(metrics,
+			prometheus.MustNewConstMetric(NotificationsLastDeliverySuccessful, prometheus.GaugeValue, successful, w.ID, w.Name, organization, project, n.ID, n.Name, string(n.DestinationType), enabled, last.Code),
+			prometheus.MustNewConstMetric(NotificationsLastDeliveryTimestamp, prometheus.GaugeValue, float64(last.SentAt.Unix()), w.ID, w.Name, organization, project, n.ID, n.Name, string(n.DestinationType), enabled),
+		)
+	}
+
+	for k, count := range counts {
+		metrics = append(metrics, prometheus.MustNewConstMetric(NotificationsConfigurations, prometheus.GaugeValue, float64(count), w.ID, w.Name, organization, project, string(k.destinationType), k.triggers, strconv.FormatBool(k.enabled)))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeNotifications) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspaceNotifications(wsCtx, w, name, config, ch)
+				})
+			}
+			return wsErrs.Wait()
+		})
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,44 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeNotifications(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Notification configurations by destination type, triggers and enabled flag", t, func() {
+		got := scrapeMetrics(t, ScrapeNotifications{}, config, NotificationsConfigurations)
+		convey.So(got, convey.ShouldHaveLength, 3)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform",
+			"destination_type": "slack", "triggers": "run:errored,run:needs_attention", "enabled": "true",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-app-frontend", "name": "app-frontend", "organization": "demo-org", "project": "Default Project",
+			"destination_type": "generic", "triggers": "run:completed,run:errored", "enabled": "false",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Last delivery of the notification configurations", t, func() {
+		got := scrapeMetrics(t, ScrapeNotifications{}, config, NotificationsLastDeliverySuccessful)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform",
+			"notification_id": "nc-network-prod-slack", "notification_name": "network-prod-alerts", "destination_type": "slack", "enabled": "true", "code": "404",
+		}, value: 0, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-app-frontend", "name": "app-frontend", "organization": "demo-org", "project": "Default Project",
+			"notification_id": "nc-app-frontend-webhook", "notification_name": "frontend-deploys", "destination_type": "generic", "enabled": "false", "code": "202",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+
+		convey.So(scrapeMetrics(t, ScrapeNotifications{}, config, NotificationsLastDeliveryTimestamp), convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform",
+			"notification_id": "nc-network-prod-slack", "notification_name": "network-prod-alerts", "destination_type": "slack", "enabled": "true",
+		}, value: 1725271500, metricType: dto.MetricType_GAUGE})
+	})
+}
@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// policies is the Metric subsystem we use.
+	policiesSubsystem = "policies"
+)
+
+// Metric descriptors.
+var (
+	PoliciesInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policiesSubsystem, "info"),
+		"Information about existing policies. The query is only set for OPA policies",
+		[]string{"id", "name", "organization", "kind", "enforcement_level", "query"}, nil,
+	)
+	PoliciesPolicySetInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policiesSubsystem, "policy_set_info"),
+		"Policy sets the policy belongs to",
+		[]string{"id", "name", "organization", "policy_set_id", "policy_set"}, nil,
+	)
+	PolicySetsAttachmentInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policysetsSubsystem, "attachment_info"),
+		"Projects and workspaces the policy set is attached to. Scope is global, project, workspace or excluded_workspace",
+		[]string{"id", "name", "organization", "scope", "project", "workspace"}, nil,
+	)
+)
+
+// ScrapePolicies scrapes the policies, their policy sets and the policy set attachments.
+type ScrapePolicies struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapePolicies{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePolicies) Name() string {
+	return policiesSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePolicies) Help() string {
+	return "Scrape information from the Policies API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/policies"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapePolicies) Version() string {
+	return "v2"
+}
+
+func getPolicyQuery(p *tfe.Policy) string {
+	if p.Query == nil {
+		return ""
+	}
+
+	return *p.Query
+}
+
+func getPoliciesListPage(ctx context.Context, page int, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	policiesList, err := config.Client.Policies.List(ctx, organization, &tfe.PolicyListOptions{
+		ListOptions: tfe.ListOptions{
+			PageSize:   pageSize,
+			PageNumber: page,
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+	}
+
+	for _, p := range policiesList.Items {
+		select {
+		case ch <- prometheus.MustNewConstMetric(
+			PoliciesInfo,
+			prometheus.GaugeValue,
+			1,
+			p.ID,
+			p.Name,
+			organization,
+			string(p.Kind),
+			string(p.EnforcementLevel),
+			getPolicyQuery(p),
+		):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// getPolicySetRelations sends the policy memberships and the attachments of the policy sets.
+func getPolicySetRelations(ctx context.Context, policySets []*tfe.PolicySet, organization string, ch chan<- prometheus.Metric) error {
+	for _, s := range policySets {
+		var metrics []prometheus.Metric
+		for _, p := range s.Policies {
+			metrics = append(metrics, prometheus.MustNewConstMetric(PoliciesPolicySetInfo, prometheus.GaugeValue, 1, p.ID, p.Name, organization, s.ID, s.Name))
+		}
+
+		if s.Global {
+			metrics = append(metrics, prometheus.MustNewConstMetric(PolicySetsAttachmentInfo, prometheus.GaugeValue, 1, s.ID, s.Name, organization, "global", "", ""))
+		}
+		for _, p := range s.Projects {
+			metrics = append(metrics, prometheus.MustNewConstMetric(PolicySetsAttachmentInfo, prometheus.GaugeValue, 1, s.ID, s.Name, organization, "project", p.Name, ""))
+		}
+		for _, w := range s.Workspaces {
+			metrics = append(metrics, prometheus.MustNewConstMetric(PolicySetsAttachmentInfo, prometheus.GaugeValue, 1, s.ID, s.Name, organization, "workspace", "", w.Name))
+		}
+		for _, w := range s.WorkspaceExclusions {
+			metrics = append(metrics, prometheus.MustNewConstMetric(PolicySetsAttachmentInfo, prometheus.GaugeValue, 1, s.ID, s.Name, organization, "excluded_workspace", "", w.Name))
+		}
+
+		for _, m := range metrics {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapePolicies) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			policiesList, err := config.Client.Policies.List(ctx, name, &tfe.PolicyListOptions{
+				ListOptions: tfe.ListOptions{
+					PageSize: pageSize,
+				}})
+
+			if err != nil {
+				return fmt.Errorf("%v, organization=%s", err, name)
+			}
+
+			for i := 1; i <= policiesList.Pagination.TotalPages; i++ {
+				if err := getPoliciesListPage(ctx, i, name, config, ch); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		g.Go(func() error {
+			policySets, err := listPolicySets(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			return getPolicySetRelations(ctx, policySets, name, ch)
+		})
+	}
+
+	return g.Wait()
+}
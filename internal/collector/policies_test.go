@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePolicies(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Policies", t, func() {
+		got := scrapeMetrics(t, ScrapePolicies{}, config, PoliciesInfo)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "pol-tags-required", "name": "tags-required", "organization": "demo-org", "kind": "opa",
+			"enforcement_level": "mandatory", "query": "data.terraform.policies.tags.deny",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Policy set membership", t, func() {
+		got := scrapeMetrics(t, ScrapePolicies{}, config, PoliciesPolicySetInfo)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "pol-encryption", "name": "encryption-at-rest", "organization": "demo-org", "policy_set_id": "polset-security", "policy_set": "security-baseline",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Policy set attachments", t, func() {
+		got := scrapeMetrics(t, ScrapePolicies{}, config, PolicySetsAttachmentInfo)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		for _, want := range []labelMap{
+			{"id": "polset-security", "name": "security-baseline", "organization": "demo-org", "scope": "global", "project": "", "workspace": ""},
+			{"id": "polset-security", "name": "security-baseline", "organization": "demo-org", "scope": "excluded_workspace", "project": "", "workspace": "sandbox"},
+			{"id": "polset-cost", "name": "cost-controls", "organization": "demo-org", "scope": "project", "project": "Platform", "workspace": ""},
+			{"id": "polset-cost", "name": "cost-controls", "organization": "demo-org", "scope": "workspace", "project": "", "workspace": "app-frontend"},
+		} {
+			convey.So(got, convey.ShouldContain, MetricResult{labels: want, value: 1, metricType: dto.MetricType_GAUGE})
+		}
+	})
+}
@@ -0,0 +1,386 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// policy is the Metric subsystem we use.
+	policySubsystem = "policy"
+	// policyevaluations is the name of the Scraper.
+	policyEvaluationsScraper = "policyevaluations"
+	// overriddenRunEvent is the action of the run events recording a policy override.
+	overriddenRunEvent = "overridden"
+)
+
+// Policy results, from best to worst.
+const (
+	policyPassed         = "passed"
+	policyAdvisoryFailed = "advisory_failed"
+	policySoftFailed     = "soft_failed"
+	policyOverridden     = "overridden"
+	policyHardFailed     = "hard_failed"
+	policyErrored        = "errored"
+)
+
+var policyResultRank = map[string]int{
+	policyPassed:         0,
+	policyAdvisoryFailed: 1,
+	policySoftFailed:     2,
+	policyOverridden:     3,
+	policyHardFailed:     4,
+	policyErrored:        5,
+}
+
+// Metric descriptors.
+var (
+	PolicyResults = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policySubsystem, "results"),
+		"Number of policy results of runs created within the lookback window. Policy set and policy are empty for legacy Sentinel policy checks",
+		[]string{"organization", "policy_set", "policy", "kind", "enforcement_level", "result"}, nil,
+	)
+	PolicySetResults = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policySubsystem, "set_results"),
+		"Number of policy set evaluations of runs created within the lookback window by their worst policy result. Policy set is empty for legacy Sentinel policy checks",
+		[]string{"organization", "policy_set", "kind", "result"}, nil,
+	)
+	PolicyOverrides = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policySubsystem, "overrides"),
+		"Number of policy overrides of runs created within the lookback window by user",
+		[]string{"organization", "user"}, nil,
+	)
+)
+
+// ScrapePolicyEvaluations scrapes the policy check and policy evaluation outcomes of recent runs.
+type ScrapePolicyEvaluations struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapePolicyEvaluations{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePolicyEvaluations) Name() string {
+	return policyEvaluationsScraper
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePolicyEvaluations) Help() string {
+	return "Scrape information from the Policy Checks and Policy Evaluations APIs: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/policy-evaluations"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapePolicyEvaluations) Version() string {
+	return "v2"
+}
+
+// getOutcomeResult maps the status and enforcement level of a policy evaluation outcome to a policy result.
+func getOutcomeResult(o tfe.Outcome, overridden bool) string {
+	switch o.Status {
+	case "passed":
+		return policyPassed
+	case "failed":
+		var result string
+		switch o.EnforcementLevel {
+		case tfe.EnforcementAdvisory:
+			return policyAdvisoryFailed
+		case tfe.EnforcementSoft:
+			result = policySoftFailed
+		default:
+			result = policyHardFailed
+		}
+		if overridden {
+			return policyOverridden
+		}
+		return result
+	default:
+		return policyErrored
+	}
+}
+
+func worstPolicyResult(a, b string) string {
+	if policyResultRank[b] > policyResultRank[a] {
+		return b
+	}
+
+	return a
+}
+
+type policyResultKey struct {
+	policySet, policy, kind, enforcementLevel, result string
+}
+
+type policySetResultKey struct {
+	policySet, kind, result string
+}
+
+// policyCounts counts policy results across concurrently scraped workspaces.
+type policyCounts struct {
+	mu         sync.Mutex
+	results    map[policyResultKey]int
+	setResults map[policySetResultKey]int
+	overrides  map[string]int
+}
+
+func (c *policyCounts) init() {
+	if c.results == nil {
+		c.results = make(map[policyResultKey]int)
+		c.setResults = make(map[policySetResultKey]int)
+		c.overrides = make(map[string]int)
+	}
+}
+
+// addPolicyCheck counts a legacy Sentinel policy check, which only reports totals.
+func (c *policyCounts) addPolicyCheck(pc *tfe.PolicyCheck) {
+	if pc.Result == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	overridden := pc.Status == tfe.PolicyOverridden
+	softFailed := policySoftFailed
+	if overridden {
+		softFailed = policyOverridden
+	}
+
+	set := policyPassed
+	for _, r := range []struct {
+		count            int
+		enforcementLevel tfe.EnforcementLevel
+		result           string
+	}{
+		{pc.Result.Passed, "", policyPassed},
+		{pc.Result.AdvisoryFailed, tfe.EnforcementAdvisory, policyAdvisoryFailed},
+		{pc.Result.SoftFailed, tfe.EnforcementSoft, softFailed},
+		{pc.Result.HardFailed, tfe.EnforcementHard, policyHardFailed},
+	} {
+		if r.count == 0 {
+			continue
+		}
+		c.results[policyResultKey{kind: string(tfe.Sentinel), enforcementLevel: string(r.enforcementLevel), result: r.result}] += r.count
+		set = worstPolicyResult(set, r.result)
+	}
+	if pc.Status == tfe.PolicyErrored {
+		set = policyErrored
+	}
+	c.setResults[policySetResultKey{kind: string(tfe.Sentinel), result: set}]++
+}
+
+// addPolicySetOutcome counts the outcomes of a policy set evaluated by a policy evaluation.
+func (c *policyCounts) addPolicySetOutcome(e *tfe.PolicyEvaluation, o *tfe.PolicySetOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	overridden := e.Status == tfe.PolicyEvaluationOverridden
+	set := policyPassed
+	for _, outcome := range o.Outcomes {
+		result := getOutcomeResult(outcome, overridden)
+		c.results[policyResultKey{
+			policySet:        o.PolicySetName,
+			policy:           outcome.PolicyName,
+			kind:             string(e.PolicyKind),
+			enforcementLevel: string(outcome.EnforcementLevel),
+			result:           result,
+		}]++
+		set = worstPolicyResult(set, result)
+	}
+	if o.Error != "" {
+		set = policyErrored
+	}
+	c.setResults[policySetResultKey{policySet: o.PolicySetName, kind: string(e.PolicyKind), result: set}]++
+}
+
+func (c *policyCounts) addOverride(user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	c.overrides[user]++
+}
+
+func listPolicyChecks(ctx context.Context, runID string, config *setup.Config) ([]*tfe.PolicyCheck, error) {
+	var checks []*tfe.PolicyCheck
+	for page := 1; ; page++ {
+		checksList, err := config.Client.PolicyChecks.List(ctx, runID, &tfe.PolicyCheckListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (run=%s, page=%d)", err, runID, page)
+		}
+
+		checks = append(checks, checksList.Items...)
+		if checksList.Pagination == nil || page >= checksList.Pagination.TotalPages {
+			return checks, nil
+		}
+	}
+}
+
+func listPolicySetOutcomes(ctx context.Context, evaluationID string, config *setup.Config) ([]*tfe.PolicySetOutcome, error) {
+	var outcomes []*tfe.PolicySetOutcome
+	for page := 1; ; page++ {
+		outcomesList, err := config.Client.PolicySetOutcomes.List(ctx, evaluationID, &tfe.PolicySetOutcomeListOptions{
+			ListOptions: &tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (policy_evaluation=%s, page=%d)", err, evaluationID, page)
+		}
+
+		outcomes = append(outcomes, outcomesList.Items...)
+		if outcomesList.Pagination == nil || page >= outcomesList.Pagination.TotalPages {
+			return outcomes, nil
+		}
+	}
+}
+
+// getOverrides counts the policy overrides recorded in the events of the run.
+func getOverrides(ctx context.Context, runID string, counts *policyCounts, config *setup.Config) error {
+	events, err := config.Client.RunEvents.List(ctx, runID, &tfe.RunEventListOptions{
+		Include: []tfe.RunEventIncludeOpt{tfe.RunEventActor},
+	})
+	if err != nil {
+		return fmt.Errorf("%v, run=%s", err, runID)
+	}
+
+	for _, e := range events.Items {
+		if e.Action != overriddenRunEvent {
+			continue
+		}
+		user := "unknown"
+		if e.Actor != nil && e.Actor.Username != "" {
+			user = e.Actor.Username
+		}
+		counts.addOverride(user)
+	}
+
+	return nil
+}
+
+func getRunPolicyResults(ctx context.Context, runID string, counts *policyCounts, config *setup.Config) error {
+	overridden := false
+
+	checks, err := listPolicyChecks(ctx, runID, config)
+	if err != nil {
+		return err
+	}
+	for _, pc := range checks {
+		counts.addPolicyCheck(pc)
+		overridden = overridden || pc.Status == tfe.PolicyOverridden
+	}
+
+	stages, err := listTaskStages(ctx, runID, config, tfe.PolicyEvaluationsTaskResults)
+	if err != nil {
+		return err
+	}
+	for _, s := range stages {
+		for _, e := range s.PolicyEvaluations {
+			outcomes, err := listPolicySetOutcomes(ctx, e.ID, config)
+			if err != nil {
+				return err
+			}
+			for _, o := range outcomes {
+				counts.addPolicySetOutcome(e, o)
+			}
+			overridden = overridden || e.Status == tfe.PolicyEvaluationOverridden
+		}
+	}
+
+	// Only the runs with an overridden policy have override events to look up.
+	if overridden {
+		return getOverrides(ctx, runID, counts, config)
+	}
+
+	return nil
+}
+
+func getWorkspacePolicyResults(ctx context.Context, w *tfe.Workspace, organization string, since time.Time, counts *policyCounts, config *setup.Config) error {
+	runs, err := listRecentRuns(ctx, w.ID, since, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+
+	for _, r := range runs {
+		if err := getRunPolicyResults(ctx, r.ID, counts, config); err != nil {
+			return fmt.Errorf("%v, organization=%s", err, organization)
+		}
+	}
+
+	return nil
+}
+
+func sendPolicyCounts(ctx context.Context, organization string, counts *policyCounts, ch chan<- prometheus.Metric) error {
+	var metrics []prometheus.Metric
+	for k, count := range counts.results {
+		metrics = append(metrics, prometheus.MustNewConstMetric(PolicyResults, prometheus.GaugeValue, float64(count), organization, k.policySet, k.policy, k.kind, k.enforcementLevel, k.result))
+	}
+	for k, count := range counts.setResults {
+		metrics = append(metrics, prometheus.MustNewConstMetric(PolicySetResults, prometheus.GaugeValue, float64(count), organization, k.policySet, k.kind, k.result))
+	}
+	for user, count := range counts.overrides {
+		metrics = append(metrics, prometheus.MustNewConstMetric(PolicyOverrides, prometheus.GaugeValue, float64(count), organization, user))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapePolicyEvaluations) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	since := time.Now().Add(-config.RunsLookback)
+
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			counts := &policyCounts{}
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspacePolicyResults(wsCtx, w, name, since, counts, config)
+				})
+			}
+			if err := wsErrs.Wait(); err != nil {
+				return err
+			}
+
+			return sendPolicyCounts(ctx, name, counts, ch)
+		})
+	}
+
+	return g.Wait()
+}
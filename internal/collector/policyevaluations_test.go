@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetOutcomeResult(t *testing.T) {
+	convey.Convey("Outcomes are mapped to policy results", t, func() {
+		for _, tc := range []struct {
+			outcome    tfe.Outcome
+			overridden bool
+			want       string
+		}{
+			{tfe.Outcome{Status: "passed", EnforcementLevel: tfe.EnforcementHard}, false, "passed"},
+			{tfe.Outcome{Status: "failed", EnforcementLevel: tfe.EnforcementAdvisory}, true, "advisory_failed"},
+			{tfe.Outcome{Status: "failed", EnforcementLevel: tfe.EnforcementSoft}, false, "soft_failed"},
+			{tfe.Outcome{Status: "failed", EnforcementLevel: tfe.EnforcementSoft}, true, "overridden"},
+			{tfe.Outcome{Status: "failed", EnforcementLevel: tfe.EnforcementMandatory}, false, "hard_failed"},
+			{tfe.Outcome{Status: "errored", EnforcementLevel: tfe.EnforcementMandatory}, false, "errored"},
+		} {
+			convey.So(getOutcomeResult(tc.outcome, tc.overridden), convey.ShouldEqual, tc.want)
+		}
+	})
+}
+
+func TestScrapePolicyEvaluations(t *testing.T) {
+	config := newDemoConfig(t)
+	// The demo runs are fixed in time, so look back far enough to include all of them.
+	config.RunsLookback = time.Since(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	convey.Convey("Policy results", t, func() {
+		got := scrapeMetrics(t, ScrapePolicyEvaluations{}, config, PolicyResults)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "policy_set": "", "policy": "", "kind": "sentinel", "enforcement_level": "soft-mandatory", "result": "overridden",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "policy_set": "cost-controls", "policy": "instance-size", "kind": "opa", "enforcement_level": "advisory", "result": "advisory_failed",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Policy set results", t, func() {
+		got := scrapeMetrics(t, ScrapePolicyEvaluations{}, config, PolicySetResults)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"organization": "demo-org", "policy_set": "cost-controls", "kind": "opa", "result": "advisory_failed"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Overrides by user", t, func() {
+		got := scrapeMetrics(t, ScrapePolicyEvaluations{}, config, PolicyOverrides)
+		convey.So(got, convey.ShouldResemble, []MetricResult{{labels: labelMap{"organization": "demo-org", "user": "alice"}, value: 1, metricType: dto.MetricType_GAUGE}})
+	})
+}
@@ -22,7 +22,22 @@ var (
 	PolicySetsInfo = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, policysetsSubsystem, "info"),
 		"Information about existing policysets",
-		[]string{"id", "name", "description", "kind", "global", "policy_count", "workspace_count", "project_count", "created_at", "updated_at", "organization"}, nil,
+		[]string{"id", "name", "description", "kind", "global", "created_at", "updated_at", "organization"}, nil,
+	)
+	PolicySetsPolicies = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policysetsSubsystem, "policies"),
+		"Number of policies in the policyset",
+		[]string{"id", "name", "organization"}, nil,
+	)
+	PolicySetsWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policysetsSubsystem, "workspaces"),
+		"Number of workspaces the policyset is attached to",
+		[]string{"id", "name", "organization"}, nil,
+	)
+	PolicySetsProjects = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, policysetsSubsystem, "projects"),
+		"Number of projects the policyset is attached to",
+		[]string{"id", "name", "organization"}, nil,
 	)
 )
 
@@ -48,38 +63,31 @@ func (ScrapePolicySets) Version() string {
 	return "v2"
 }
 
-func getPolicySetsListPage(ctx context.Context, page int, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
-	policysetsList, err := config.Client.PolicySets.List(ctx, organization, &tfe.PolicySetListOptions{
-		ListOptions: tfe.ListOptions{
-			PageSize:   pageSize,
-			PageNumber: page,
-		},
-	})
-
-	if err != nil {
-		return fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
-	}
-
-	for _, p := range policysetsList.Items {
-		select {
-		case ch <- prometheus.MustNewConstMetric(
-			PolicySetsInfo,
-			prometheus.GaugeValue,
-			1,
-			p.ID,
-			p.Name,
-			p.Description,
-			string(p.Kind),
-			strconv.FormatBool(p.Global),
-			strconv.Itoa(p.PolicyCount),
-			strconv.Itoa(p.WorkspaceCount),
-			strconv.Itoa(p.ProjectCount),
-			p.CreatedAt.String(),
-			p.UpdatedAt.String(),
-			p.Organization.Name,
-		):
-		case <-ctx.Done():
-			return ctx.Err()
+func getPolicySetMetrics(ctx context.Context, policySets []*tfe.PolicySet, organization string, ch chan<- prometheus.Metric) error {
+	for _, p := range policySets {
+		for _, m := range []prometheus.Metric{
+			prometheus.MustNewConstMetric(
+				PolicySetsInfo,
+				prometheus.GaugeValue,
+				1,
+				p.ID,
+				p.Name,
+				p.Description,
+				string(p.Kind),
+				strconv.FormatBool(p.Global),
+				p.CreatedAt.String(),
+				p.UpdatedAt.String(),
+				organization,
+			),
+			prometheus.MustNewConstMetric(PolicySetsPolicies, prometheus.GaugeValue, float64(p.PolicyCount), p.ID, p.Name, organization),
+			prometheus.MustNewConstMetric(PolicySetsWorkspaces, prometheus.GaugeValue, float64(p.WorkspaceCount), p.ID, p.Name, organization),
+			prometheus.MustNewConstMetric(PolicySetsProjects, prometheus.GaugeValue, float64(p.ProjectCount), p.ID, p.Name, organization),
+		} {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 	}
 
@@ -91,24 +99,44 @@ func (ScrapePolicySets) Scrape(ctx context.Context, config *setup.Config, ch cha
 	for _, name := range config.Organizations {
 		name := name
 		g.Go(func() error {
-			policysetsList, err := config.Client.PolicySets.List(ctx, name, &tfe.PolicySetListOptions{
-				ListOptions: tfe.ListOptions{
-					PageSize: pageSize,
-				}})
-
+			policySets, err := listPolicySets(ctx, name, config)
 			if err != nil {
-				return fmt.Errorf("%v, organization=%s", err, name)
+				return err
 			}
 
-			for i := 1; i <= policysetsList.Pagination.TotalPages; i++ {
-				if err := getPolicySetsListPage(ctx, i, name, config, ch); err != nil {
-					return err
-				}
-			}
-
-			return nil
+			return getPolicySetMetrics(ctx, policySets, name, ch)
 		})
 	}
 
 	return g.Wait()
 }
+
+// listPolicySets returns all the policy sets of the organization, with their policies, workspaces, projects
+// and workspace exclusions included. It is shared by the scrapers of a refresh that need the policy sets.
+func listPolicySets(ctx context.Context, organization string, config *setup.Config) ([]*tfe.PolicySet, error) {
+	return sharedListing(ctx, "policysets/"+organization, func(ctx context.Context) ([]*tfe.PolicySet, error) {
+		var policySets []*tfe.PolicySet
+		for page := 1; ; page++ {
+			policysetsList, err := config.Client.PolicySets.List(ctx, organization, &tfe.PolicySetListOptions{
+				ListOptions: tfe.ListOptions{
+					PageSize:   pageSize,
+					PageNumber: page,
+				},
+				Include: []tfe.PolicySetIncludeOpt{
+					tfe.PolicySetPolicies,
+					tfe.PolicySetWorkspaces,
+					tfe.PolicySetProjects,
+					tfe.PolicySetWorkspaceExclusions,
+				},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+			}
+
+			policySets = append(policySets, policysetsList.Items...)
+			if policysetsList.Pagination == nil || page >= policysetsList.Pagination.TotalPages {
+				return policySets, nil
+			}
+		}
+	})
+}
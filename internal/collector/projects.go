@@ -104,3 +104,24 @@ func (ScrapeProjects) Scrape(ctx context.Context, config *setup.Config, ch chan<
 
 	return g.Wait()
 }
+
+// listProjects returns all the projects of the organization.
+func listProjects(ctx context.Context, organization string, config *setup.Config) ([]*tfe.Project, error) {
+	var projects []*tfe.Project
+	for page := 1; ; page++ {
+		projectsList, err := config.Client.Projects.List(ctx, organization, &tfe.ProjectListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+		}
+
+		projects = append(projects, projectsList.Items...)
+		if projectsList.Pagination == nil || page >= projectsList.Pagination.TotalPages {
+			return projects, nil
+		}
+	}
+}
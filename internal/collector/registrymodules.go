@@ -2,10 +2,15 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/go-version"
 	"github.com/nicolaka/tfbi/internal/setup"
 	"golang.org/x/sync/errgroup"
 
@@ -22,10 +27,47 @@ var (
 	RegistryModulesInfo = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "info"),
 		"Information about existing registrymodules",
-		[]string{"id", "name", "provider", "registry_name", "no_code", "status", "created_at", "updated_at", "organization"}, nil,
+		[]string{"id", "name", "provider", "registry_name", "no_code", "status", "created_at", "updated_at", "organization", "publishing"}, nil,
+	)
+	RegistryModulesVersions = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "versions"),
+		"Number of versions of the registry module",
+		[]string{"id", "name", "provider", "organization"}, nil,
+	)
+	RegistryModulesVersionInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "version_info"),
+		"Versions of the registry module and their status: ok, errored or pending",
+		[]string{"id", "name", "provider", "organization", "version", "status"}, nil,
+	)
+	RegistryModulesLatestVersion = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "latest_version_info"),
+		"Latest version of the registry module with an ok status",
+		[]string{"id", "name", "provider", "organization", "version"}, nil,
+	)
+	RegistryModulesLatestVersionPublished = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "latest_version_published_timestamp_seconds"),
+		"Time the latest version of the registry module was published",
+		[]string{"id", "name", "provider", "organization", "version"}, nil,
+	)
+	RegistryModulesWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "workspaces"),
+		"Number of workspaces whose current state was created by a configuration calling the registry module",
+		[]string{"id", "name", "provider", "organization"}, nil,
+	)
+	RegistryModulesVersionWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registrymodulesSubsystem, "version_workspaces"),
+		"Number of workspaces whose current state was created by a configuration calling the registry module, by the version constraint of the module call (not the resolved version)",
+		[]string{"id", "name", "provider", "organization", "version_constraint"}, nil,
 	)
 )
 
+// Statuses of the registry module versions.
+const (
+	moduleVersionOK      = "ok"
+	moduleVersionErrored = "errored"
+	moduleVersionPending = "pending"
+)
+
 // ScrapeRegistryModules scrapes metrics about the registrymodules.
 type ScrapeRegistryModules struct{}
 
@@ -50,7 +92,224 @@ func (ScrapeRegistryModules) Version() string {
 
 // []string{"id", "name", "provider", "registry-name","no-code", "status", "created-at","updated-at"}, nil,
 
-func getModulesListPage(ctx context.Context, page int, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+// getPublishing returns how the versions of the registry module are published: from the branch or tags
+// of its VCS repository, or through the API.
+func getPublishing(m *tfe.RegistryModule) string {
+	if m.VCSRepo == nil {
+		return "api"
+	}
+	if m.PublishingMechanism == tfe.PublishingMechanismBranch {
+		return "branch"
+	}
+
+	return "tag"
+}
+
+// getModuleVersionStatus reduces the status of a registry module version to ok, errored or pending.
+func getModuleVersionStatus(s tfe.RegistryModuleVersionStatus) string {
+	switch s {
+	case tfe.RegistryModuleVersionStatusOk:
+		return moduleVersionOK
+	case tfe.RegistryModuleVersionStatusCloneFailed, tfe.RegistryModuleVersionStatusRegIngressReqFailed, tfe.RegistryModuleVersionStatusRegIngressFailed:
+		return moduleVersionErrored
+	default:
+		return moduleVersionPending
+	}
+}
+
+// getLatestModuleVersion returns the latest version of the registry module with an ok status, empty if none.
+func getLatestModuleVersion(m *tfe.RegistryModule) string {
+	var latest *version.Version
+	for _, s := range m.VersionStatuses {
+		if s.Status != tfe.RegistryModuleVersionStatusOk {
+			continue
+		}
+		v, err := version.NewVersion(s.Version)
+		if err != nil {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+
+	return latest.Original()
+}
+
+func getModuleVersions(ctx context.Context, m *tfe.RegistryModule, organization string, config *setup.Config) ([]prometheus.Metric, error) {
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(RegistryModulesVersions, prometheus.GaugeValue, float64(len(m.VersionStatuses)), m.ID, m.Name, m.Provider, organization),
+	}
+	for _, s := range m.VersionStatuses {
+		metrics = append(metrics, prometheus.MustNewConstMetric(RegistryModulesVersionInfo, prometheus.GaugeValue, 1, m.ID, m.Name, m.Provider, organization, s.Version, getModuleVersionStatus(s.Status)))
+	}
+
+	latest := getLatestModuleVersion(m)
+	if latest == "" {
+		return metrics, nil
+	}
+	metrics = append(metrics, prometheus.MustNewConstMetric(RegistryModulesLatestVersion, prometheus.GaugeValue, 1, m.ID, m.Name, m.Provider, organization, latest))
+
+	// The version statuses don't tell when versions were published, and public modules can't be read from the organization.
+	if m.RegistryName != tfe.PrivateRegistry {
+		return metrics, nil
+	}
+	v, err := config.Client.RegistryModules.ReadVersion(ctx, tfe.RegistryModuleID{
+		Organization: organization,
+		Namespace:    m.Namespace,
+		Name:         m.Name,
+		Provider:     m.Provider,
+		RegistryName: m.RegistryName,
+	}, latest)
+	if errors.Is(err, tfe.ErrResourceNotFound) {
+		return metrics, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v, (organization=%s, module=%s)", err, organization, m.ID)
+	}
+	if published, err := time.Parse(time.RFC3339, v.CreatedAt); err == nil {
+		metrics = append(metrics, prometheus.MustNewConstMetric(RegistryModulesLatestVersionPublished, prometheus.GaugeValue, float64(published.Unix()), m.ID, m.Name, m.Provider, organization, latest))
+	}
+
+	return metrics, nil
+}
+
+// Hostnames of the public registry, which sources without hostname are read from, and of the private registry of
+// the Terraform Enterprise instance a configuration runs on.
+const (
+	publicRegistryHostname  = "registry.terraform.io"
+	genericRegistryHostname = "localterraform.com"
+)
+
+// getRegistryModuleAddress returns the hostname/namespace/name/provider of a registry module source,
+// like app.terraform.io/demo-org/vpc/aws for app.terraform.io/demo-org/vpc/aws//modules/subnets.
+// The generic hostname is replaced by the private registry's hostname.
+func getRegistryModuleAddress(source, privateHostname string) (string, bool) {
+	if strings.HasPrefix(source, ".") || strings.Contains(source, "::") || strings.Contains(source, "://") {
+		return "", false
+	}
+	// Terraform reads these from the VCS host, not from a registry.
+	if strings.HasPrefix(source, "github.com/") || strings.HasPrefix(source, "bitbucket.org/") {
+		return "", false
+	}
+	source, _, _ = strings.Cut(source, "//")
+
+	parts := strings.Split(source, "/")
+	switch len(parts) {
+	case 3:
+		return publicRegistryHostname + "/" + strings.Join(parts, "/"), true
+	case 4:
+		hostname := strings.ToLower(parts[0])
+		if hostname == genericRegistryHostname {
+			hostname = privateHostname
+		}
+		return hostname + "/" + strings.Join(parts[1:], "/"), true
+	default:
+		return "", false
+	}
+}
+
+// getRegistryModuleSource returns the hostname/namespace/name/provider workspaces call the registry module with.
+func getRegistryModuleSource(m *tfe.RegistryModule, privateHostname string) string {
+	hostname := privateHostname
+	if m.RegistryName == tfe.PublicRegistry {
+		hostname = publicRegistryHostname
+	}
+
+	return strings.ToLower(hostname) + "/" + m.Namespace + "/" + m.Name + "/" + m.Provider
+}
+
+type moduleVersionKey struct {
+	module, version string
+}
+
+// moduleConsumers counts the workspaces calling the registry modules, by hostname/namespace/name/provider.
+type moduleConsumers struct {
+	privateHostname string
+
+	mu         sync.Mutex
+	workspaces map[string]int
+	versions   map[string]map[string]int
+}
+
+func (c *moduleConsumers) add(calls map[string]planModuleCall) {
+	modules := make(map[string]bool)
+	versions := make(map[moduleVersionKey]bool)
+	for _, call := range calls {
+		module, ok := getRegistryModuleAddress(call.Source, c.privateHostname)
+		if !ok {
+			continue
+		}
+		modules[module] = true
+		versions[moduleVersionKey{module: module, version: call.VersionConstraint}] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for module := range modules {
+		c.workspaces[module]++
+	}
+	for k := range versions {
+		if c.versions[k.module] == nil {
+			c.versions[k.module] = make(map[string]int)
+		}
+		c.versions[k.module][k.version]++
+	}
+}
+
+// getModuleConsumers reads the module calls of the plan that created the current state of every workspace.
+func getModuleConsumers(ctx context.Context, organization string, config *setup.Config) (*moduleConsumers, error) {
+	consumers := &moduleConsumers{
+		privateHostname: config.RegistryModulesHostname,
+		workspaces:      make(map[string]int),
+		versions:        make(map[string]map[string]int),
+	}
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	workspaces, err := listWorkspaces(ctx, organization, config)
+	if err != nil {
+		return nil, err
+	}
+
+	wsErrs, wsCtx := errgroup.WithContext(ctx)
+	for _, w := range workspaces {
+		w := w
+		wsErrs.Go(func() error {
+			sem <- struct{}{}        // acquire
+			defer func() { <-sem }() // release
+
+			sv, err := readCurrentStateVersion(wsCtx, w.ID, config)
+			if err != nil || sv == nil {
+				return err
+			}
+			calls, err := readModuleCalls(wsCtx, sv.Run, config)
+			if err != nil {
+				return fmt.Errorf("%v, (organization=%s, workspace=%s)", err, organization, w.ID)
+			}
+			consumers.add(calls)
+			return nil
+		})
+	}
+
+	return consumers, wsErrs.Wait()
+}
+
+func (c *moduleConsumers) metrics(m *tfe.RegistryModule, organization string) []prometheus.Metric {
+	module := getRegistryModuleSource(m, c.privateHostname)
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(RegistryModulesWorkspaces, prometheus.GaugeValue, float64(c.workspaces[module]), m.ID, m.Name, m.Provider, organization),
+	}
+	for v, count := range c.versions[module] {
+		metrics = append(metrics, prometheus.MustNewConstMetric(RegistryModulesVersionWorkspaces, prometheus.GaugeValue, float64(count), m.ID, m.Name, m.Provider, organization, v))
+	}
+
+	return metrics
+}
+
+func getModulesListPage(ctx context.Context, page int, organization string, consumers *moduleConsumers, config *setup.Config, ch chan<- prometheus.Metric) error {
 	registrymodulesList, err := config.Client.RegistryModules.List(ctx, organization, &tfe.RegistryModuleListOptions{
 		ListOptions: tfe.ListOptions{
 			PageSize:   pageSize,
@@ -63,23 +322,38 @@ func getModulesListPage(ctx context.Context, page int, organization string, conf
 	}
 
 	for _, m := range registrymodulesList.Items {
-		select {
-		case ch <- prometheus.MustNewConstMetric(
-			RegistryModulesInfo,
-			prometheus.GaugeValue,
-			1,
-			m.ID,
-			m.Name,
-			m.Provider,
-			string(m.RegistryName),
-			strconv.FormatBool(m.NoCode),
-			string(m.Status),
-			m.CreatedAt,
-			m.UpdatedAt,
-			m.Organization.Name,
-		):
-		case <-ctx.Done():
-			return ctx.Err()
+		versions, err := getModuleVersions(ctx, m, organization, config)
+		if err != nil {
+			return err
+		}
+
+		metrics := append([]prometheus.Metric{
+			prometheus.MustNewConstMetric(
+				RegistryModulesInfo,
+				prometheus.GaugeValue,
+				1,
+				m.ID,
+				m.Name,
+				m.Provider,
+				string(m.RegistryName),
+				strconv.FormatBool(m.NoCode),
+				string(m.Status),
+				m.CreatedAt,
+				m.UpdatedAt,
+				m.Organization.Name,
+				getPublishing(m),
+			),
+		}, versions...)
+		if consumers != nil {
+			metrics = append(metrics, consumers.metrics(m, organization)...)
+		}
+
+		for _, metric := range metrics {
+			select {
+			case ch <- metric:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 	}
 
@@ -100,8 +374,15 @@ func (ScrapeRegistryModules) Scrape(ctx context.Context, config *setup.Config, c
 				return fmt.Errorf("%v, organization=%s", err, name)
 			}
 
+			var consumers *moduleConsumers
+			if config.RegistryModulesConsumers {
+				if consumers, err = getModuleConsumers(ctx, name, config); err != nil {
+					return err
+				}
+			}
+
 			for i := 1; i <= registrymodulesList.Pagination.TotalPages; i++ {
-				if err := getModulesListPage(ctx, i, name, config, ch); err != nil {
+				if err := getModulesListPage(ctx, i, name, consumers, config, ch); err != nil {
 					return err
 				}
 			}
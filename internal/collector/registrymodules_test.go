@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/fakeapi"
+	"github.com/nicolaka/tfbi/internal/setup"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeRegistryModules(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Registry modules with their publishing mechanism", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesInfo)
+		publishing := make(map[string]string)
+		for _, m := range got {
+			publishing[m.labels["name"]] = m.labels["publishing"]
+		}
+		convey.So(publishing, convey.ShouldResemble, map[string]string{"vpc": "tag", "bucket": "api"})
+	})
+
+	convey.Convey("Versions of the registry modules with their status", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesVersionInfo)
+		convey.So(got, convey.ShouldHaveLength, 6)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-vpc", "name": "vpc", "provider": "aws", "organization": "demo-org", "version": "1.5.1", "status": "errored"}, value: 1, metricType: dto.MetricType_GAUGE})
+
+		got = scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesVersions)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-vpc", "name": "vpc", "provider": "aws", "organization": "demo-org"}, value: 4, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Latest ok version and when it was published", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesLatestVersionPublished)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-vpc", "name": "vpc", "provider": "aws", "organization": "demo-org", "version": "1.5.0"}, value: 1719792000, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-bucket", "name": "bucket", "provider": "aws", "organization": "demo-org", "version": "1.1.0"}, value: 1714521600, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Consumers are only counted when enabled", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesWorkspaces)
+		convey.So(got, convey.ShouldBeEmpty)
+
+		config := newDemoConfig(t)
+		config.RegistryModulesConsumers = true
+		config.RegistryModulesHostname = "app.terraform.io"
+		got = scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesWorkspaces)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-vpc", "name": "vpc", "provider": "aws", "organization": "demo-org"}, value: 1, metricType: dto.MetricType_GAUGE})
+
+		got = scrapeMetrics(t, ScrapeRegistryModules{}, config, RegistryModulesVersionWorkspaces)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-bucket", "name": "bucket", "provider": "aws", "organization": "demo-org", "version_constraint": "1.1.0"}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "mod-vpc", "name": "vpc", "provider": "aws", "organization": "demo-org", "version_constraint": "1.4.0"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+}
+
+func TestGetRegistryModuleAddress(t *testing.T) {
+	convey.Convey("Registry module sources", t, func() {
+		for source, want := range map[string]string{
+			"app.terraform.io/demo-org/vpc/aws":                  "app.terraform.io/demo-org/vpc/aws",
+			"App.Terraform.io/demo-org/vpc/aws":                  "app.terraform.io/demo-org/vpc/aws",
+			"localterraform.com/demo-org/vpc/aws":                "app.terraform.io/demo-org/vpc/aws",
+			"terraform-aws-modules/vpc/aws":                      "registry.terraform.io/terraform-aws-modules/vpc/aws",
+			"demo-org/vpc/aws":                                   "registry.terraform.io/demo-org/vpc/aws",
+			"app.terraform.io/demo-org/vpc/aws//modules/subnets": "app.terraform.io/demo-org/vpc/aws",
+		} {
+			got, ok := getRegistryModuleAddress(source, "app.terraform.io")
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(got, convey.ShouldEqual, want)
+		}
+		for _, source := range []string{"./modules/vpc", "git::https://example.com/vpc.git", "github.com/demo-org/vpc"} {
+			_, ok := getRegistryModuleAddress(source, "app.terraform.io")
+			convey.So(ok, convey.ShouldBeFalse)
+		}
+	})
+}
+
+func TestRegistryModulesShareStateVersions(t *testing.T) {
+	dataset, err := fakeapi.Demo()
+	if err != nil {
+		t.Fatalf("error loading demo dataset: %s", err)
+	}
+	var mu sync.Mutex
+	reads := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/current-state-version") || strings.HasSuffix(r.URL.Path, "/json-output") {
+			mu.Lock()
+			reads[r.URL.Path]++
+			mu.Unlock()
+		}
+		fakeapi.NewServer(dataset).ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := tfe.NewClient(&tfe.Config{Address: server.URL, Token: "test"})
+	if err != nil {
+		t.Fatalf("error creating a stub api client: %s", err)
+	}
+	e := New(setup.Config{Client: *client, CLI: setup.CLI{Organizations: []string{"demo-org"}}, Logger: log.NewNopLogger()}, NewMetrics())
+	e.scrapers = []Scraper{ScrapeStateVersions{}, ScrapeRegistryModules{}}
+
+	convey.Convey("State versions and plans are read once per refresh for the state versions and registry modules collectors", t, func() {
+		e.Refresh(context.Background())
+		convey.So(readMetric(e.metrics.Error).value, convey.ShouldEqual, 0)
+		convey.So(reads, convey.ShouldNotBeEmpty)
+		for _, n := range reads {
+			convey.So(n, convey.ShouldEqual, 1)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// registryproviders is the Metric subsystem we use.
+	registryprovidersSubsystem = "registryproviders"
+)
+
+// Metric descriptors.
+var (
+	RegistryProvidersInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registryprovidersSubsystem, "info"),
+		"Information about existing registry providers",
+		[]string{"id", "name", "namespace", "registry_name", "created_at", "updated_at", "organization"}, nil,
+	)
+	RegistryProvidersVersions = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registryprovidersSubsystem, "versions"),
+		"Number of versions of the private registry provider",
+		[]string{"id", "name", "namespace", "organization"}, nil,
+	)
+	RegistryProvidersVersionInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registryprovidersSubsystem, "version_info"),
+		"Versions of the private registry provider with the GPG key they are signed with and their protocols",
+		[]string{"id", "name", "namespace", "organization", "version", "key_id", "protocols", "shasums_uploaded", "shasums_sig_uploaded"}, nil,
+	)
+	RegistryProvidersVersionPlatforms = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registryprovidersSubsystem, "version_platforms"),
+		"Number of platforms published for the version of the private registry provider",
+		[]string{"id", "name", "namespace", "organization", "version"}, nil,
+	)
+	RegistryProvidersPlatformInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registryprovidersSubsystem, "platform_info"),
+		"Platforms published for the version of the private registry provider and whether their binary was uploaded",
+		[]string{"id", "name", "namespace", "organization", "version", "os", "arch", "binary_uploaded"}, nil,
+	)
+	RegistryProvidersGPGKeyVersions = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, registryprovidersSubsystem, "gpg_key_versions"),
+		"Number of private registry provider versions signed with the GPG key",
+		[]string{"organization", "key_id"}, nil,
+	)
+)
+
+// ScrapeRegistryProviders scrapes metrics about the registry providers.
+type ScrapeRegistryProviders struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeRegistryProviders{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeRegistryProviders) Name() string {
+	return registryprovidersSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeRegistryProviders) Help() string {
+	return "Scrape information from the Registry Providers API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/private-registry/providers"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeRegistryProviders) Version() string {
+	return "v2"
+}
+
+func listProviderVersions(ctx context.Context, providerID tfe.RegistryProviderID, config *setup.Config) ([]*tfe.RegistryProviderVersion, error) {
+	var versions []*tfe.RegistryProviderVersion
+	for page := 1; ; page++ {
+		versionsList, err := config.Client.RegistryProviderVersions.List(ctx, providerID, &tfe.RegistryProviderVersionListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, provider=%s/%s, page=%d)", err, providerID.OrganizationName, providerID.Namespace, providerID.Name, page)
+		}
+
+		versions = append(versions, versionsList.Items...)
+		if versionsList.Pagination == nil || page >= versionsList.Pagination.TotalPages {
+			return versions, nil
+		}
+	}
+}
+
+func listProviderPlatforms(ctx context.Context, versionID tfe.RegistryProviderVersionID, config *setup.Config) ([]*tfe.RegistryProviderPlatform, error) {
+	var platforms []*tfe.RegistryProviderPlatform
+	for page := 1; ; page++ {
+		platformsList, err := config.Client.RegistryProviderPlatforms.List(ctx, versionID, &tfe.RegistryProviderPlatformListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, provider=%s/%s, version=%s, page=%d)", err, versionID.OrganizationName, versionID.Namespace, versionID.Name, versionID.Version, page)
+		}
+
+		platforms = append(platforms, platformsList.Items...)
+		if platformsList.Pagination == nil || page >= platformsList.Pagination.TotalPages {
+			return platforms, nil
+		}
+	}
+}
+
+// getProviderVersions returns the metrics of the versions and platforms of a private registry provider, and counts
+// the versions signed with each GPG key.
+func getProviderVersions(ctx context.Context, p *tfe.RegistryProvider, organization string, keys map[string]int, config *setup.Config) ([]prometheus.Metric, error) {
+	providerID := tfe.RegistryProviderID{
+		OrganizationName: organization,
+		RegistryName:     p.RegistryName,
+		Namespace:        p.Namespace,
+		Name:             p.Name,
+	}
+	versions, err := listProviderVersions(ctx, providerID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(RegistryProvidersVersions, prometheus.GaugeValue, float64(len(versions)), p.ID, p.Name, p.Namespace, organization),
+	}
+	for _, v := range versions {
+		keys[v.KeyID]++
+
+		platforms, err := listProviderPlatforms(ctx, tfe.RegistryProviderVersionID{RegistryProviderID: providerID, Version: v.Version}, config)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics = append(metrics,
+			prometheus.MustNewConstMetric(RegistryProvidersVersionInfo, prometheus.GaugeValue, 1, p.ID, p.Name, p.Namespace, organization, v.Version, v.KeyID,
+				strings.Join(v.Protocols, ","), strconv.FormatBool(v.ShasumsUploaded), strconv.FormatBool(v.ShasumsSigUploaded)),
+			prometheus.MustNewConstMetric(RegistryProvidersVersionPlatforms, prometheus.GaugeValue, float64(len(platforms)), p.ID, p.Name, p.Namespace, organization, v.Version),
+		)
+		for _, pl := range platforms {
+			metrics = append(metrics, prometheus.MustNewConstMetric(RegistryProvidersPlatformInfo, prometheus.GaugeValue, 1, p.ID, p.Name, p.Namespace, organization, v.Version, pl.OS, pl.Arch, strconv.FormatBool(pl.ProviderBinaryUploaded)))
+		}
+	}
+
+	return metrics, nil
+}
+
+func getProvidersListPage(ctx context.Context, page int, organization string, keys map[string]int, config *setup.Config, ch chan<- prometheus.Metric) error {
+	registryprovidersList, err := config.Client.RegistryProviders.List(ctx, organization, &tfe.RegistryProviderListOptions{
+		ListOptions: tfe.ListOptions{
+			PageSize:   pageSize,
+			PageNumber: page,
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+	}
+
+	for _, p := range registryprovidersList.Items {
+		metrics := []prometheus.Metric{
+			prometheus.MustNewConstMetric(
+				RegistryProvidersInfo,
+				prometheus.GaugeValue,
+				1,
+				p.ID,
+				p.Name,
+				p.Namespace,
+				string(p.RegistryName),
+				p.CreatedAt,
+				p.UpdatedAt,
+				organization,
+			),
+		}
+		// Versions are only published for private providers, public ones are curated from the public registry.
+		if p.RegistryName == tfe.PrivateRegistry {
+			versions, err := getProviderVersions(ctx, p, organization, keys, config)
+			if err != nil {
+				return err
+			}
+			metrics = append(metrics, versions...)
+		}
+
+		for _, m := range metrics {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ScrapeRegistryProviders) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			registryprovidersList, err := config.Client.RegistryProviders.List(ctx, name, &tfe.RegistryProviderListOptions{
+				ListOptions: tfe.ListOptions{
+					PageSize: pageSize,
+				}})
+
+			if err != nil {
+				return fmt.Errorf("%v, organization=%s", err, name)
+			}
+
+			keys := make(map[string]int)
+			for i := 1; i <= registryprovidersList.Pagination.TotalPages; i++ {
+				if err := getProvidersListPage(ctx, i, name, keys, config, ch); err != nil {
+					return err
+				}
+			}
+
+			for key, count := range keys {
+				select {
+				case ch <- prometheus.MustNewConstMetric(RegistryProvidersGPGKeyVersions, prometheus.GaugeValue, float64(count), name, key):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeRegistryProviders(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Private and public registry providers", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryProviders{}, config, RegistryProvidersInfo)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		registries := make(map[string]string)
+		for _, m := range got {
+			registries[m.labels["namespace"]+"/"+m.labels["name"]] = m.labels["registry_name"]
+		}
+		convey.So(registries, convey.ShouldResemble, map[string]string{"demo-org/internal": "private", "hashicorp/aws": "public"})
+	})
+
+	convey.Convey("Versions of the private providers only", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryProviders{}, config, RegistryProvidersVersions)
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"id": "prov-internal", "name": "internal", "namespace": "demo-org", "organization": "demo-org"}, value: 2, metricType: dto.MetricType_GAUGE},
+		})
+
+		got = scrapeMetrics(t, ScrapeRegistryProviders{}, config, RegistryProvidersVersionInfo)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "prov-internal", "name": "internal", "namespace": "demo-org", "organization": "demo-org", "version": "2.0.0", "key_id": "32966F3FB5AC1129", "protocols": "6.0", "shasums_uploaded": "true", "shasums_sig_uploaded": "true"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Platforms published per version", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryProviders{}, config, RegistryProvidersVersionPlatforms)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "prov-internal", "name": "internal", "namespace": "demo-org", "organization": "demo-org", "version": "2.0.0"}, value: 2, metricType: dto.MetricType_GAUGE})
+
+		got = scrapeMetrics(t, ScrapeRegistryProviders{}, config, RegistryProvidersPlatformInfo)
+		convey.So(got, convey.ShouldHaveLength, 3)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "prov-internal", "name": "internal", "namespace": "demo-org", "organization": "demo-org", "version": "2.0.0", "os": "darwin", "arch": "arm64", "binary_uploaded": "false"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Versions signed with each GPG key", t, func() {
+		got := scrapeMetrics(t, ScrapeRegistryProviders{}, config, RegistryProvidersGPGKeyVersions)
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"organization": "demo-org", "key_id": "32966F3FB5AC1129"}, value: 2, metricType: dto.MetricType_GAUGE},
+		})
+	})
+}
@@ -0,0 +1,341 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// runs is the Metric subsystem we use.
+	runsSubsystem = "runs"
+)
+
+// Metric descriptors.
+var (
+	RunsInWindow = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, runsSubsystem, "in_window"),
+		"Number of runs created within the lookback window. This is a gauge over a sliding window, not a counter: don't use rate() on it",
+		[]string{"organization", "project", "workspace", "status", "source", "trigger_reason", "plan_only", "is_destroy"}, nil,
+	)
+	RunsWindowDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, runsSubsystem, "window_duration_seconds"),
+		"Time runs created within the lookback window spent in the queue, plan or apply phase. The histogram only holds the runs of the window, so its buckets go down as runs leave it: use histogram_quantile() on it directly, without rate()",
+		[]string{"organization", "project", "phase"}, nil,
+	)
+)
+
+// runDurationBuckets spans one second to a bit over an hour.
+var runDurationBuckets = prometheus.ExponentialBuckets(1, 2, 13)
+
+// ScrapeRuns scrapes metrics about the recent runs of every workspace.
+type ScrapeRuns struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeRuns{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeRuns) Name() string {
+	return runsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeRuns) Help() string {
+	return "Scrape information from the Runs API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/run"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeRuns) Version() string {
+	return "v2"
+}
+
+// run holds the run attributes used by the runs collector. It is decoded directly
+// instead of using tfe.Run, because go-tfe doesn't decode the trigger-reason attribute.
+type run struct {
+	ID               string                   `jsonapi:"primary,runs"`
+	CreatedAt        time.Time                `jsonapi:"attr,created-at,iso8601"`
+	IsDestroy        bool                     `jsonapi:"attr,is-destroy"`
+	PlanOnly         bool                     `jsonapi:"attr,plan-only"`
+	Source           tfe.RunSource            `jsonapi:"attr,source"`
+	Status           tfe.RunStatus            `jsonapi:"attr,status"`
+	StatusTimestamps *tfe.RunStatusTimestamps `jsonapi:"attr,status-timestamps"`
+	TriggerReason    string                   `jsonapi:"attr,trigger-reason"`
+
+	// Relations
+	ConfigurationVersion *tfe.ConfigurationVersion `jsonapi:"relation,configuration-version"`
+}
+
+type runList struct {
+	*tfe.Pagination
+	Items []*run
+}
+
+// listRecentRuns returns the runs of the workspace created after since. Runs are listed
+// newest first, so paging stops at the first run older than since.
+func listRecentRuns(ctx context.Context, workspaceID string, since time.Time, config *setup.Config) ([]*run, error) {
+	var runs []*run
+	for page := 1; ; page++ {
+		req, err := config.Client.NewRequest("GET", fmt.Sprintf("workspaces/%s/runs", url.PathEscape(workspaceID)), &tfe.RunListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			Include: []tfe.RunIncludeOpt{tfe.RunConfigVer},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rl := &runList{}
+		if err := req.Do(ctx, rl); err != nil {
+			return nil, fmt.Errorf("%v, (workspace=%s, page=%d)", err, workspaceID, page)
+		}
+
+		for _, r := range rl.Items {
+			if r.CreatedAt.Before(since) {
+				return runs, nil
+			}
+			runs = append(runs, r)
+		}
+
+		if rl.Pagination == nil || page >= rl.Pagination.TotalPages {
+			return runs, nil
+		}
+	}
+}
+
+// getRunSource maps the run and configuration version sources to ui, vcs, api or cli.
+func getRunSource(r *run) string {
+	switch r.Source {
+	case tfe.RunSourceUI:
+		return "ui"
+	case tfe.RunSourceAPI:
+		return "api"
+	case "terraform", "terraform+cloud":
+		return "cli"
+	case tfe.RunSourceConfigurationVersion:
+		if r.ConfigurationVersion == nil {
+			return "api"
+		}
+		switch r.ConfigurationVersion.Source {
+		case tfe.ConfigurationSourceTerraform:
+			return "cli"
+		case tfe.ConfigurationSourceAPI, "":
+			return "api"
+		default:
+			return "vcs"
+		}
+	}
+
+	return string(r.Source)
+}
+
+func getRunTriggerReason(r *run) string {
+	if r.TriggerReason == "" {
+		return "unknown"
+	}
+
+	return r.TriggerReason
+}
+
+// runCountKey identifies a tf_runs_window_count series of a workspace.
+type runCountKey struct {
+	status, source, triggerReason string
+	planOnly, isDestroy           bool
+}
+
+// durationHistogram accumulates the observations of a run phase within the lookback window.
+type durationHistogram struct {
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+func (h *durationHistogram) observe(start, end time.Time) {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return
+	}
+
+	if h.buckets == nil {
+		h.buckets = make(map[float64]uint64, len(runDurationBuckets))
+		for _, b := range runDurationBuckets {
+			h.buckets[b] = 0
+		}
+	}
+
+	v := end.Sub(start).Seconds()
+	h.count++
+	h.sum += v
+	for _, b := range runDurationBuckets {
+		if v <= b {
+			h.buckets[b]++
+		}
+	}
+}
+
+// runDurations aggregates the run phase durations of a project.
+type runDurations struct {
+	queue, plan, apply durationHistogram
+}
+
+func (d *runDurations) observe(r *run) {
+	ts := r.StatusTimestamps
+	if ts == nil {
+		return
+	}
+
+	d.queue.observe(ts.PlanQueuedAt, ts.PlanningAt)
+	d.plan.observe(ts.PlanningAt, firstNonZero(ts.PlannedAt, ts.PlannedAndFinishedAt, ts.ErroredAt))
+	d.apply.observe(ts.ApplyingAt, firstNonZero(ts.AppliedAt, ts.ErroredAt))
+}
+
+// projectRunDurations aggregates run phase durations per project across concurrently scraped workspaces.
+type projectRunDurations struct {
+	mu        sync.Mutex
+	byProject map[string]*runDurations
+}
+
+func (p *projectRunDurations) observe(project string, r *run) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.byProject == nil {
+		p.byProject = make(map[string]*runDurations)
+	}
+	if p.byProject[project] == nil {
+		p.byProject[project] = &runDurations{}
+	}
+	p.byProject[project].observe(r)
+}
+
+func firstNonZero(tt ...time.Time) time.Time {
+	for _, t := range tt {
+		if !t.IsZero() {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func getWorkspaceRuns(ctx context.Context, w *tfe.Workspace, organization string, since time.Time, durations *projectRunDurations, config *setup.Config, ch chan<- prometheus.Metric) error {
+	runs, err := listRecentRuns(ctx, w.ID, since, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+
+	counts := make(map[runCountKey]int)
+	for _, r := range runs {
+		counts[runCountKey{
+			status:        string(r.Status),
+			source:        getRunSource(r),
+			triggerReason: getRunTriggerReason(r),
+			planOnly:      r.PlanOnly,
+			isDestroy:     r.IsDestroy,
+		}]++
+		durations.observe(getProjectName(w), r)
+	}
+
+	for k, count := range counts {
+		select {
+		case ch <- prometheus.MustNewConstMetric(
+			RunsInWindow,
+			prometheus.GaugeValue,
+			float64(count),
+			organization,
+			getProjectName(w),
+			w.Name,
+			k.status,
+			k.source,
+			k.triggerReason,
+			strconv.FormatBool(k.planOnly),
+			strconv.FormatBool(k.isDestroy),
+		):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func sendRunDurations(ctx context.Context, organization, project string, d *runDurations, ch chan<- prometheus.Metric) error {
+	var metrics []prometheus.Metric
+	for _, p := range []struct {
+		phase string
+		h     *durationHistogram
+	}{
+		{"queue", &d.queue},
+		{"plan", &d.plan},
+		{"apply", &d.apply},
+	} {
+		if p.h.count == 0 {
+			continue
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstHistogram(RunsWindowDuration, p.h.count, p.h.sum, p.h.buckets, organization, project, p.phase))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeRuns) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	since := time.Now().Add(-config.RunsLookback)
+
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			durations := &projectRunDurations{}
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspaceRuns(wsCtx, w, name, since, durations, config, ch)
+				})
+			}
+			if err := wsErrs.Wait(); err != nil {
+				return err
+			}
+
+			for project, d := range durations.byProject {
+				if err := sendRunDurations(ctx, name, project, d, ch); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
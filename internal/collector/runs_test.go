@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	tfe "github.com/hashicorp/go-tfe"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetRunSource(t *testing.T) {
+	convey.Convey("getRunSource", t, func() {
+		convey.So(getRunSource(&run{Source: tfe.RunSourceUI}), convey.ShouldEqual, "ui")
+		convey.So(getRunSource(&run{Source: tfe.RunSourceAPI}), convey.ShouldEqual, "api")
+		convey.So(getRunSource(&run{Source: "terraform+cloud"}), convey.ShouldEqual, "cli")
+		convey.So(getRunSource(&run{
+			Source:               tfe.RunSourceConfigurationVersion,
+			ConfigurationVersion: &tfe.ConfigurationVersion{Source: tfe.ConfigurationSourceGithub},
+		}), convey.ShouldEqual, "vcs")
+		convey.So(getRunSource(&run{
+			Source:               tfe.RunSourceConfigurationVersion,
+			ConfigurationVersion: &tfe.ConfigurationVersion{Source: tfe.ConfigurationSourceTerraform},
+		}), convey.ShouldEqual, "cli")
+	})
+}
+
+func TestRunDurations(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &runDurations{}
+	d.observe(&run{StatusTimestamps: &tfe.RunStatusTimestamps{
+		PlanQueuedAt: start,
+		PlanningAt:   start.Add(3 * time.Second),
+		PlannedAt:    start.Add(60 * time.Second),
+	}})
+
+	convey.Convey("Run phase durations", t, func() {
+		convey.So(d.queue.count, convey.ShouldEqual, 1)
+		convey.So(d.queue.sum, convey.ShouldEqual, 3)
+		convey.So(d.queue.buckets[2], convey.ShouldEqual, 0)
+		convey.So(d.queue.buckets[4], convey.ShouldEqual, 1)
+		convey.So(d.plan.sum, convey.ShouldEqual, 57)
+		convey.So(d.apply.count, convey.ShouldEqual, 0)
+	})
+}
+
+func TestScrapeRuns(t *testing.T) {
+	config := newDemoConfig(t)
+	// The demo runs are fixed in time, so look back far enough to include all of them.
+	config.RunsLookback = time.Since(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	convey.Convey("Recent runs are counted per workspace", t, func() {
+		got := scrapeMetrics(t, ScrapeRuns{}, config, RunsInWindow)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "project": "Platform", "workspace": "network-prod", "status": "applied",
+			"source": "vcs", "trigger_reason": "manual", "plan_only": "false", "is_destroy": "false",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "project": "Platform", "workspace": "network-dev", "status": "planned_and_finished",
+			"source": "cli", "trigger_reason": "manual", "plan_only": "true", "is_destroy": "false",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Run phase durations are histograms per project and phase", t, func() {
+		got := scrapeMetrics(t, ScrapeRuns{}, config, RunsWindowDuration)
+		convey.So(got, convey.ShouldHaveLength, 6)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "project": "Platform", "phase": "plan",
+		}, value: 3, metricType: dto.MetricType_HISTOGRAM})
+	})
+}
@@ -0,0 +1,280 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// runtasks is the Metric subsystem we use.
+	runtasksSubsystem = "runtasks"
+)
+
+// Metric descriptors.
+var (
+	RunTasksInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, runtasksSubsystem, "info"),
+		"Information about existing run tasks",
+		[]string{"id", "name", "organization", "category", "enabled"}, nil,
+	)
+	RunTasksWorkspaceTaskInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, runtasksSubsystem, "workspace_task_info"),
+		"Run tasks attached to a workspace, with one series per stage the task runs in",
+		[]string{"id", "task_id", "task", "organization", "project", "workspace", "enforcement_level", "stage"}, nil,
+	)
+	RunTasksResults = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, runtasksSubsystem, "results"),
+		"Number of run task results of runs created within the lookback window, by outcome",
+		[]string{"organization", "task_id", "task", "stage", "enforcement_level", "status"}, nil,
+	)
+)
+
+// ScrapeRunTasks scrapes metrics about the run tasks and their recent results.
+type ScrapeRunTasks struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeRunTasks{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeRunTasks) Name() string {
+	return runtasksSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeRunTasks) Help() string {
+	return "Scrape information from the Run Tasks API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/run-tasks/run-tasks"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeRunTasks) Version() string {
+	return "v2"
+}
+
+// taskStageListOptions adds the include parameter that tfe.TaskStageListOptions lacks.
+type taskStageListOptions struct {
+	tfe.ListOptions
+	Include []tfe.TaskStageIncludeOpt `url:"include,omitempty"`
+}
+
+type taskStageList struct {
+	*tfe.Pagination
+	Items []*tfe.TaskStage
+}
+
+// listTaskStages returns the task stages of the run, with the given relations included.
+func listTaskStages(ctx context.Context, runID string, config *setup.Config, include ...tfe.TaskStageIncludeOpt) ([]*tfe.TaskStage, error) {
+	var stages []*tfe.TaskStage
+	for page := 1; ; page++ {
+		req, err := config.Client.NewRequest("GET", fmt.Sprintf("runs/%s/task-stages", url.PathEscape(runID)), &taskStageListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			Include: include,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sl := &taskStageList{}
+		if err := req.Do(ctx, sl); err != nil {
+			return nil, fmt.Errorf("%v, (run=%s, page=%d)", err, runID, page)
+		}
+
+		stages = append(stages, sl.Items...)
+		if sl.Pagination == nil || page >= sl.Pagination.TotalPages {
+			return stages, nil
+		}
+	}
+}
+
+func listRunTasks(ctx context.Context, organization string, config *setup.Config) ([]*tfe.RunTask, error) {
+	var tasks []*tfe.RunTask
+	for page := 1; ; page++ {
+		tasksList, err := config.Client.RunTasks.List(ctx, organization, &tfe.RunTaskListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			Include: []tfe.RunTaskIncludeOpt{tfe.RunTaskWorkspaceTasks},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+		}
+
+		tasks = append(tasks, tasksList.Items...)
+		if tasksList.Pagination == nil || page >= tasksList.Pagination.TotalPages {
+			return tasks, nil
+		}
+	}
+}
+
+// getWorkspaceTaskStages returns the stages the workspace task runs in.
+func getWorkspaceTaskStages(wt *tfe.WorkspaceRunTask) []tfe.Stage {
+	if len(wt.Stages) > 0 {
+		return wt.Stages
+	}
+	if wt.Stage != "" {
+		return []tfe.Stage{wt.Stage}
+	}
+
+	return nil
+}
+
+// taskResultKey identifies a tf_runtasks_results series of an organization.
+type taskResultKey struct {
+	taskID, task, stage, enforcementLevel, status string
+}
+
+// taskResultCounts counts task results across concurrently scraped workspaces.
+type taskResultCounts struct {
+	mu     sync.Mutex
+	counts map[taskResultKey]int
+}
+
+func (c *taskResultCounts) add(stage *tfe.TaskStage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[taskResultKey]int)
+	}
+	for _, r := range stage.TaskResults {
+		c.counts[taskResultKey{
+			taskID:           r.TaskID,
+			task:             r.TaskName,
+			stage:            string(stage.Stage),
+			enforcementLevel: string(r.WorkspaceTaskEnforcementLevel),
+			status:           string(r.Status),
+		}]++
+	}
+}
+
+func getWorkspaceTaskResults(ctx context.Context, w *tfe.Workspace, organization string, since time.Time, results *taskResultCounts, config *setup.Config) error {
+	runs, err := listRecentRuns(ctx, w.ID, since, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+
+	for _, r := range runs {
+		stages, err := listTaskStages(ctx, r.ID, config, tfe.TaskStageTaskResults)
+		if err != nil {
+			return fmt.Errorf("%v, organization=%s", err, organization)
+		}
+		for _, s := range stages {
+			results.add(s)
+		}
+	}
+
+	return nil
+}
+
+func getOrganizationRunTasks(ctx context.Context, organization string, since time.Time, sem chan struct{}, config *setup.Config, ch chan<- prometheus.Metric) error {
+	tasks, err := listRunTasks(ctx, organization, config)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	workspaces, err := listWorkspaces(ctx, organization, config)
+	if err != nil {
+		return err
+	}
+	workspacesByID := make(map[string]*tfe.Workspace, len(workspaces))
+	for _, w := range workspaces {
+		workspacesByID[w.ID] = w
+	}
+
+	var metrics []prometheus.Metric
+	attached := make(map[string]*tfe.Workspace)
+	for _, t := range tasks {
+		metrics = append(metrics, prometheus.MustNewConstMetric(RunTasksInfo, prometheus.GaugeValue, 1, t.ID, t.Name, organization, t.Category, strconv.FormatBool(t.Enabled)))
+
+		for _, wt := range t.WorkspaceRunTasks {
+			if wt == nil || wt.Workspace == nil {
+				continue
+			}
+			w, ok := workspacesByID[wt.Workspace.ID]
+			if !ok {
+				continue
+			}
+			attached[w.ID] = w
+
+			for _, stage := range getWorkspaceTaskStages(wt) {
+				metrics = append(metrics, prometheus.MustNewConstMetric(
+					RunTasksWorkspaceTaskInfo,
+					prometheus.GaugeValue,
+					1,
+					wt.ID,
+					t.ID,
+					t.Name,
+					organization,
+					getProjectName(w),
+					w.Name,
+					string(wt.EnforcementLevel),
+					string(stage),
+				))
+			}
+		}
+	}
+
+	// Only the runs of workspaces with run tasks attached can have task results.
+	results := &taskResultCounts{}
+	wsErrs, wsCtx := errgroup.WithContext(ctx)
+	for _, w := range attached {
+		w := w
+		wsErrs.Go(func() error {
+			sem <- struct{}{}        // acquire
+			defer func() { <-sem }() // release
+			return getWorkspaceTaskResults(wsCtx, w, organization, since, results, config)
+		})
+	}
+	if err := wsErrs.Wait(); err != nil {
+		return err
+	}
+
+	for k, count := range results.counts {
+		metrics = append(metrics, prometheus.MustNewConstMetric(RunTasksResults, prometheus.GaugeValue, float64(count), organization, k.taskID, k.task, k.stage, k.enforcementLevel, k.status))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeRunTasks) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	since := time.Now().Add(-config.RunsLookback)
+
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			return getOrganizationRunTasks(ctx, name, since, sem, config, ch)
+		})
+	}
+
+	return g.Wait()
+}
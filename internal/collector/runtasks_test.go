@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeRunTasks(t *testing.T) {
+	config := newDemoConfig(t)
+	// The demo runs are fixed in time, so look back far enough to include all of them.
+	config.RunsLookback = time.Since(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	convey.Convey("Run tasks", t, func() {
+		got := scrapeMetrics(t, ScrapeRunTasks{}, config, RunTasksInfo)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "task-cost", "name": "cost-estimator", "organization": "demo-org", "category": "task", "enabled": "false"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Workspace attachments have a series per stage", t, func() {
+		got := scrapeMetrics(t, ScrapeRunTasks{}, config, RunTasksWorkspaceTaskInfo)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "wstask-scanner-network-prod", "task_id": "task-scanner", "task": "security-scanner", "organization": "demo-org",
+			"project": "Platform", "workspace": "network-prod", "enforcement_level": "mandatory", "stage": "pre_apply",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Task results of recent runs by outcome", t, func() {
+		got := scrapeMetrics(t, ScrapeRunTasks{}, config, RunTasksResults)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "task_id": "task-scanner", "task": "security-scanner", "stage": "post_plan",
+			"enforcement_level": "mandatory", "status": "unreachable",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+}
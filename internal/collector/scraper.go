@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/nicolaka/tfbi/internal/setup"
 
@@ -23,3 +24,14 @@ type Scraper interface {
 	// Scrape collects data from a particular terraform cloud/enterprise API and sends it over channel as prometheus metric.
 	Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error
 }
+
+// StatefulScraper is a Scraper that carries state between its runs, like the cursor of an incremental API
+// and the counters it accumulates. The Exporter keeps the state returned by the last successful run,
+// and persists it across restarts when a state file is configured.
+type StatefulScraper interface {
+	Scraper
+
+	// ScrapeState is like Scrape, given the state returned by the last successful run, nil on the first run.
+	// It returns the state for the next run.
+	ScrapeState(ctx context.Context, config *setup.Config, state json.RawMessage, ch chan<- prometheus.Metric) (json.RawMessage, error)
+}
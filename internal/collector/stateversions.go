@@ -0,0 +1,244 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// stateversions is the name of the Scraper, its metrics belong to the state subsystem.
+	stateVersionsSubsystem = "stateversions"
+	// state is the Metric subsystem we use.
+	stateSubsystem = "state"
+	// rootModule is the module of the resources declared in the root module.
+	rootModule = "root"
+)
+
+// Metric descriptors.
+var (
+	StateProviderResources = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, stateSubsystem, "provider_resources"),
+		"Number of resources of the provider in the workspace's current state version",
+		[]string{"id", "name", "organization", "project", "provider"}, nil,
+	)
+	StateResourceTypeResources = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, stateSubsystem, "resource_type_resources"),
+		"Number of resources of the type in the workspace's current state version",
+		[]string{"id", "name", "organization", "project", "provider", "type"}, nil,
+	)
+	StateModuleResources = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, stateSubsystem, "module_resources"),
+		"Number of resources of the module in the workspace's current state version, with the module source and the version constraint (not the resolved version) of the module call in the configuration of the run that created it",
+		[]string{"id", "name", "organization", "project", "module", "source", "version_constraint"}, nil,
+	)
+)
+
+// ScrapeStateVersions scrapes the providers, resource types and modules of the workspaces' current state versions.
+type ScrapeStateVersions struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeStateVersions{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeStateVersions) Name() string {
+	return stateVersionsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeStateVersions) Help() string {
+	return "Scrape information from the State Versions API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/state-versions"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeStateVersions) Version() string {
+	return "v2"
+}
+
+// planModule is a module of the configuration section of a plan's JSON output.
+type planModule struct {
+	ModuleCalls map[string]planModuleCall `json:"module_calls"`
+}
+
+type planModuleCall struct {
+	Source            string     `json:"source"`
+	VersionConstraint string     `json:"version_constraint"`
+	Module            planModule `json:"module"`
+}
+
+type planJSONOutput struct {
+	Configuration struct {
+		RootModule planModule `json:"root_module"`
+	} `json:"configuration"`
+}
+
+// moduleCalls returns the module calls of m and its child modules by module address, like module.vpc.module.subnets.
+func (m planModule) moduleCalls(prefix string, calls map[string]planModuleCall) map[string]planModuleCall {
+	for name, call := range m.ModuleCalls {
+		address := prefix + "module." + name
+		calls[address] = call
+		call.Module.moduleCalls(address+".", calls)
+	}
+
+	return calls
+}
+
+// readModuleCalls returns the module calls of the plan of the run. The state doesn't record the module sources,
+// so they are unknown for state versions that weren't created by a run or whose plan can't be read.
+// The plan is read once per refresh for all the scrapers, so the calls must not be modified.
+func readModuleCalls(ctx context.Context, r *tfe.Run, config *setup.Config) (map[string]planModuleCall, error) {
+	if r == nil || r.Plan == nil {
+		return make(map[string]planModuleCall), nil
+	}
+
+	return sharedListing(ctx, "modulecalls/"+r.Plan.ID, func(ctx context.Context) (map[string]planModuleCall, error) {
+		calls := make(map[string]planModuleCall)
+		b, err := config.Client.Plans.ReadJSONOutput(ctx, r.Plan.ID)
+		if errors.Is(err, tfe.ErrUnauthorized) || errors.Is(err, tfe.ErrResourceNotFound) {
+			return calls, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v, plan=%s", err, r.Plan.ID)
+		}
+
+		p := &planJSONOutput{}
+		if err := json.Unmarshal(b, p); err != nil {
+			return nil, fmt.Errorf("%v, plan=%s", err, r.Plan.ID)
+		}
+
+		return p.Configuration.RootModule.moduleCalls("", calls), nil
+	})
+}
+
+// getProviderSource returns the source address of a provider configuration address,
+// like registry.terraform.io/hashicorp/aws for provider["registry.terraform.io/hashicorp/aws"].west.
+func getProviderSource(provider string) string {
+	_, source, ok := strings.Cut(provider, `provider["`)
+	if !ok {
+		return provider
+	}
+	source, _, _ = strings.Cut(source, `"]`)
+
+	return source
+}
+
+var moduleInstanceKey = regexp.MustCompile(`\[[^\]]*\]`)
+
+// getModuleAddress returns the address of the module of a resource, without the instance keys of count and for_each.
+func getModuleAddress(module string) string {
+	if module == "" {
+		return rootModule
+	}
+
+	return moduleInstanceKey.ReplaceAllString(module, "")
+}
+
+type resourceTypeKey struct {
+	provider, typ string
+}
+
+// readCurrentStateVersion returns the current state version of the workspace with its run, nil if it has no state.
+// It is read once per refresh for all the scrapers.
+func readCurrentStateVersion(ctx context.Context, workspaceID string, config *setup.Config) (*tfe.StateVersion, error) {
+	return sharedListing(ctx, "stateversions/"+workspaceID, func(ctx context.Context) (*tfe.StateVersion, error) {
+		sv, err := config.Client.StateVersions.ReadCurrentWithOptions(ctx, workspaceID, &tfe.StateVersionCurrentOptions{
+			Include: []tfe.StateVersionIncludeOpt{tfe.SVrun},
+		})
+		if errors.Is(err, tfe.ErrResourceNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v, workspace=%s", err, workspaceID)
+		}
+
+		return sv, nil
+	})
+}
+
+func getWorkspaceStateResources(ctx context.Context, w *tfe.Workspace, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	sv, err := readCurrentStateVersion(ctx, w.ID, config)
+	if err != nil {
+		return fmt.Errorf("%v, organization=%s", err, organization)
+	}
+	if sv == nil {
+		return nil
+	}
+
+	calls, err := readModuleCalls(ctx, sv.Run, config)
+	if err != nil {
+		return fmt.Errorf("%v, (organization=%s, workspace=%s)", err, organization, w.ID)
+	}
+
+	providers := make(map[string]int)
+	types := make(map[resourceTypeKey]int)
+	modules := make(map[string]int)
+	for _, r := range sv.Resources {
+		provider := getProviderSource(r.Provider)
+		providers[provider] += r.Count
+		types[resourceTypeKey{provider: provider, typ: r.Type}] += r.Count
+		modules[getModuleAddress(r.Module)] += r.Count
+	}
+
+	project := getProjectName(w)
+	var metrics []prometheus.Metric
+	for provider, count := range providers {
+		metrics = append(metrics, prometheus.MustNewConstMetric(StateProviderResources, prometheus.GaugeValue, float64(count), w.ID, w.Name, organization, project, provider))
+	}
+	for k, count := range types {
+		metrics = append(metrics, prometheus.MustNewConstMetric(StateResourceTypeResources, prometheus.GaugeValue, float64(count), w.ID, w.Name, organization, project, k.provider, k.typ))
+	}
+	for module, count := range modules {
+		call := calls[module]
+		metrics = append(metrics, prometheus.MustNewConstMetric(StateModuleResources, prometheus.GaugeValue, float64(count), w.ID, w.Name, organization, project, module, call.Source, call.VersionConstraint))
+	}
+
+	for _, m := range metrics {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeStateVersions) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspaceStateResources(wsCtx, w, name, config, ch)
+				})
+			}
+			return wsErrs.Wait()
+		})
+	}
+
+	return g.Wait()
+}
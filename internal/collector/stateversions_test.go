@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeStateVersions(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Resources per provider, ignoring provider aliases", t, func() {
+		got := scrapeMetrics(t, ScrapeStateVersions{}, config, StateProviderResources)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform", "provider": "registry.terraform.io/hashicorp/aws"}, value: 15, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-app-frontend", "name": "app-frontend", "organization": "demo-org", "project": "Default Project", "provider": "registry.terraform.io/hashicorp/random"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Resources per resource type", t, func() {
+		got := scrapeMetrics(t, ScrapeStateVersions{}, config, StateResourceTypeResources)
+		convey.So(got, convey.ShouldHaveLength, 8)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-network-dev", "name": "network-dev", "organization": "demo-org", "project": "Platform", "provider": "registry.terraform.io/hashicorp/aws", "type": "aws_subnet"}, value: 4, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Resources per module with the source and version of the run's plan", t, func() {
+		got := scrapeMetrics(t, ScrapeStateVersions{}, config, StateModuleResources)
+		convey.So(got, convey.ShouldHaveLength, 5)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform", "module": "module.vpc", "source": "app.terraform.io/demo-org/vpc/aws", "version_constraint": "1.4.0"}, value: 14, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform", "module": "root", "source": "", "version_constraint": ""}, value: 1, metricType: dto.MetricType_GAUGE})
+		// The state of network-dev wasn't created by a run, so its module sources are unknown.
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-network-dev", "name": "network-dev", "organization": "demo-org", "project": "Platform", "module": "module.vpc", "source": "", "version_constraint": ""}, value: 5, metricType: dto.MetricType_GAUGE})
+	})
+}
+
+func TestGetModuleAddress(t *testing.T) {
+	convey.Convey("Module addresses without instance keys", t, func() {
+		convey.So(getModuleAddress(""), convey.ShouldEqual, "root")
+		convey.So(getModuleAddress("module.vpc[0]"), convey.ShouldEqual, "module.vpc")
+		convey.So(getModuleAddress(`module.vpc["eu"].module.subnets`), convey.ShouldEqual, "module.vpc.module.subnets")
+	})
+}
@@ -0,0 +1,251 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// team_access is the Metric subsystem we use.
+	teamAccessSubsystem = "team_access"
+	// teamworkspaces and teamprojects are the names of the Scrapers, named after the API endpoints.
+	teamWorkspacesScraper = "teamworkspaces"
+	teamProjectsScraper   = "teamprojects"
+)
+
+// Metric descriptors.
+var (
+	TeamAccessInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, teamAccessSubsystem, "info"),
+		"Access granted to a team on a workspace or a project, with its custom permissions (empty when not applicable)",
+		[]string{
+			"organization", "team_id", "team", "scope", "project", "workspace", "access",
+			"runs", "variables", "state_versions", "sentinel_mocks", "workspace_locking", "run_tasks",
+			"workspace_create", "workspace_move", "workspace_delete",
+			"project_settings", "project_teams", "project_variable_sets",
+		}, nil,
+	)
+)
+
+// ScrapeTeamWorkspaceAccess scrapes the access teams have on every workspace.
+type ScrapeTeamWorkspaceAccess struct{}
+
+// ScrapeTeamProjectAccess scrapes the access teams have on every project.
+type ScrapeTeamProjectAccess struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeTeamWorkspaceAccess{}, ScrapeTeamProjectAccess{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTeamWorkspaceAccess) Name() string {
+	return teamWorkspacesScraper
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTeamWorkspaceAccess) Help() string {
+	return "Scrape information from the Team Access API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/team-access"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeTeamWorkspaceAccess) Version() string {
+	return "v2"
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTeamProjectAccess) Name() string {
+	return teamProjectsScraper
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTeamProjectAccess) Help() string {
+	return "Scrape information from the Team Project Access API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/project-team-access"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeTeamProjectAccess) Version() string {
+	return "v2"
+}
+
+func getTeamName(teamNames map[string]string, t *tfe.Team) (id, name string) {
+	if t == nil {
+		return "", ""
+	}
+
+	return t.ID, teamNames[t.ID]
+}
+
+func getWorkspaceTeamAccess(ctx context.Context, w *tfe.Workspace, organization string, teamNames map[string]string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	for page := 1; ; page++ {
+		accessList, err := config.Client.TeamAccess.List(ctx, &tfe.TeamAccessListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			WorkspaceID: w.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("%v, (organization=%s, workspace=%s, page=%d)", err, organization, w.Name, page)
+		}
+
+		for _, a := range accessList.Items {
+			teamID, team := getTeamName(teamNames, a.Team)
+			select {
+			case ch <- prometheus.MustNewConstMetric(
+				TeamAccessInfo,
+				prometheus.GaugeValue,
+				1,
+				organization,
+				teamID,
+				team,
+				"workspace",
+				getProjectName(w),
+				w.Name,
+				string(a.Access),
+				string(a.Runs),
+				string(a.Variables),
+				string(a.StateVersions),
+				string(a.SentinelMocks),
+				strconv.FormatBool(a.WorkspaceLocking),
+				strconv.FormatBool(a.RunTasks),
+				"", "", "", "", "", "",
+			):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if accessList.Pagination == nil || page >= accessList.Pagination.TotalPages {
+			return nil
+		}
+	}
+}
+
+func getProjectTeamAccess(ctx context.Context, p *tfe.Project, organization string, teamNames map[string]string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	for page := 1; ; page++ {
+		accessList, err := config.Client.TeamProjectAccess.List(ctx, tfe.TeamProjectAccessListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			ProjectID: p.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("%v, (organization=%s, project=%s, page=%d)", err, organization, p.Name, page)
+		}
+
+		for _, a := range accessList.Items {
+			teamID, team := getTeamName(teamNames, a.Team)
+			ws := a.WorkspaceAccess
+			if ws == nil {
+				ws = &tfe.TeamProjectAccessWorkspacePermissions{}
+			}
+			prj := a.ProjectAccess
+			if prj == nil {
+				prj = &tfe.TeamProjectAccessProjectPermissions{}
+			}
+
+			select {
+			case ch <- prometheus.MustNewConstMetric(
+				TeamAccessInfo,
+				prometheus.GaugeValue,
+				1,
+				organization,
+				teamID,
+				team,
+				"project",
+				p.Name,
+				"",
+				string(a.Access),
+				string(ws.WorkspaceRunsPermission),
+				string(ws.WorkspaceVariablesPermission),
+				string(ws.WorkspaceStateVersionsPermission),
+				string(ws.WorkspaceSentinelMocksPermission),
+				strconv.FormatBool(ws.WorkspaceLockingPermission),
+				strconv.FormatBool(ws.WorkspaceRunTasksPermission),
+				strconv.FormatBool(ws.WorkspaceCreatePermission),
+				strconv.FormatBool(ws.WorkspaceMovePermission),
+				strconv.FormatBool(ws.WorkspaceDeletePermission),
+				string(prj.ProjectSettingsPermission),
+				string(prj.ProjectTeamsPermission),
+				string(prj.ProjectVariableSetsPermission),
+			):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if accessList.Pagination == nil || page >= accessList.Pagination.TotalPages {
+			return nil
+		}
+	}
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeTeamWorkspaceAccess) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			teamNames, err := listTeamNames(ctx, name, config)
+			if err != nil {
+				return err
+			}
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspaceTeamAccess(wsCtx, w, name, teamNames, config, ch)
+				})
+			}
+			return wsErrs.Wait()
+		})
+	}
+
+	return g.Wait()
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeTeamProjectAccess) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			teamNames, err := listTeamNames(ctx, name, config)
+			if err != nil {
+				return err
+			}
+			projects, err := listProjects(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			for _, p := range projects {
+				if err := getProjectTeamAccess(ctx, p, name, teamNames, config, ch); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
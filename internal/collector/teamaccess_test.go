@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeTeamAccess(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Team workspace access", t, func() {
+		got := scrapeMetrics(t, ScrapeTeamWorkspaceAccess{}, config, TeamAccessInfo)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"organization": "demo-org", "team_id": "team-owners", "team": "owners", "scope": "workspace",
+			"project": "Platform", "workspace": "network-prod", "access": "admin",
+			"runs": "apply", "variables": "write", "state_versions": "write", "sentinel_mocks": "read",
+			"workspace_locking": "true", "run_tasks": "true",
+			"workspace_create": "", "workspace_move": "", "workspace_delete": "",
+			"project_settings": "", "project_teams": "", "project_variable_sets": "",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Team project access", t, func() {
+		got := scrapeMetrics(t, ScrapeTeamProjectAccess{}, config, TeamAccessInfo)
+		convey.So(got, convey.ShouldResemble, []MetricResult{{labels: labelMap{
+			"organization": "demo-org", "team_id": "team-platform", "team": "platform", "scope": "project",
+			"project": "Platform", "workspace": "", "access": "custom",
+			"runs": "plan", "variables": "read", "state_versions": "read-outputs", "sentinel_mocks": "none",
+			"workspace_locking": "false", "run_tasks": "false",
+			"workspace_create": "true", "workspace_move": "false", "workspace_delete": "false",
+			"project_settings": "read", "project_teams": "none", "project_variable_sets": "manage",
+		}, value: 1, metricType: dto.MetricType_GAUGE}})
+	})
+}
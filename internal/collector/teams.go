@@ -3,7 +3,6 @@ package collector
 import (
 	"context"
 	"fmt"
-	"strconv"
 
 	"golang.org/x/sync/errgroup"
 
@@ -23,7 +22,12 @@ var (
 	TeamsInfo = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, teamsSubsystem, "info"),
 		"Information about existing teams",
-		[]string{"id", "name", "sso_team_id", "users_count"}, nil,
+		[]string{"id", "name", "sso_team_id"}, nil,
+	)
+	TeamsUsers = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, teamsSubsystem, "users"),
+		"Number of users in the team",
+		[]string{"id", "name", "organization"}, nil,
 	)
 )
 
@@ -65,20 +69,23 @@ func getTeamsListPage(ctx context.Context, page int, organization string, config
 	}
 
 	for _, t := range teamsList.Items {
-		select {
-		case ch <- prometheus.MustNewConstMetric(
-			TeamsInfo,
-			prometheus.GaugeValue,
-			1,
-			t.ID,
-			t.Name,
-			t.SSOTeamID,
-			strconv.Itoa(t.UserCount),
-		):
-		case <-ctx.Done():
-			return ctx.Err()
+		for _, m := range []prometheus.Metric{
+			prometheus.MustNewConstMetric(
+				TeamsInfo,
+				prometheus.GaugeValue,
+				1,
+				t.ID,
+				t.Name,
+				t.SSOTeamID,
+			),
+			prometheus.MustNewConstMetric(TeamsUsers, prometheus.GaugeValue, float64(t.UserCount), t.ID, t.Name, organization),
+		} {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
-
 	}
 
 	return nil
@@ -110,3 +117,26 @@ func (ScrapeTeams) Scrape(ctx context.Context, config *setup.Config, ch chan<- p
 
 	return g.Wait()
 }
+
+// listTeamNames returns the names of the organization's teams by id.
+func listTeamNames(ctx context.Context, organization string, config *setup.Config) (map[string]string, error) {
+	names := make(map[string]string)
+	for page := 1; ; page++ {
+		teamsList, err := config.Client.Teams.List(ctx, organization, &tfe.TeamListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+		}
+
+		for _, t := range teamsList.Items {
+			names[t.ID] = t.Name
+		}
+		if teamsList.Pagination == nil || page >= teamsList.Pagination.TotalPages {
+			return names, nil
+		}
+	}
+}
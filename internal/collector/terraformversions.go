@@ -0,0 +1,304 @@
+package collector
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/go-version"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// terraformversions is the name of the Scraper, its metrics belong to the workspaces subsystem.
+	terraformVersionsScraper = "terraformversions"
+	// terraform is the Metric subsystem of the available versions.
+	terraformSubsystem = "terraform"
+)
+
+// Sources of the available Terraform versions.
+const (
+	versionsSourceFile    = "file"
+	versionsSourceAdmin   = "admin"
+	versionsSourceBundled = "bundled"
+)
+
+// bundledTerraformVersions is used when no versions file is set and the admin API isn't available,
+// as on Terraform Cloud or without an admin token.
+//
+//go:embed terraform_versions.json
+var bundledTerraformVersions []byte
+
+// Metric descriptors.
+var (
+	TerraformLatestVersion = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, terraformSubsystem, "latest_version_info"),
+		"Latest available Terraform version and where the list of versions came from (file, admin or bundled)",
+		[]string{"version", "source"}, nil,
+	)
+	WorkspacesTerraformVersionsBehind = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "terraform_versions_behind_latest"),
+		"Number of available Terraform versions newer than the workspace's version",
+		[]string{"id", "name", "organization", "project", "terraform_version"}, nil,
+	)
+	WorkspacesTerraformMajorVersionsBehind = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "terraform_major_versions_behind"),
+		"Number of major Terraform versions between the workspace's version and the latest version",
+		[]string{"id", "name", "organization", "project", "terraform_version"}, nil,
+	)
+	WorkspacesTerraformMinorVersionsBehind = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "terraform_minor_versions_behind"),
+		"Number of minor Terraform versions newer than the workspace's version",
+		[]string{"id", "name", "organization", "project", "terraform_version"}, nil,
+	)
+	WorkspacesTerraformUnsupported = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "terraform_unsupported"),
+		"Whether the workspace's Terraform version is deprecated or older than the supported minor versions (1 for unsupported, 0 otherwise)",
+		[]string{"id", "name", "organization", "project", "terraform_version"}, nil,
+	)
+)
+
+// ScrapeTerraformVersions scrapes how far behind the latest Terraform version the workspaces are.
+type ScrapeTerraformVersions struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeTerraformVersions{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTerraformVersions) Name() string {
+	return terraformVersionsScraper
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTerraformVersions) Help() string {
+	return "Scrape information from the Terraform Versions Admin API: https://developer.hashicorp.com/terraform/enterprise/api-docs/admin/terraform-versions"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeTerraformVersions) Version() string {
+	return "v2"
+}
+
+// terraformVersion is an available Terraform version, as found in the versions file.
+type terraformVersion struct {
+	Version    string `json:"version"`
+	Deprecated bool   `json:"deprecated"`
+}
+
+type terraformVersionsFile struct {
+	Versions []terraformVersion `json:"versions"`
+}
+
+// terraformVersions holds the available Terraform versions, newest first.
+type terraformVersions struct {
+	versions []*version.Version
+	// deprecated tells whether every available version is deprecated.
+	deprecated map[string]bool
+	source     string
+}
+
+func newTerraformVersions(available []terraformVersion, source string) (*terraformVersions, error) {
+	tv := &terraformVersions{deprecated: make(map[string]bool), source: source}
+	for _, a := range available {
+		v, err := version.NewVersion(a.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Terraform version %q: %v", a.Version, err)
+		}
+		// Pre-releases are not upgrade targets.
+		if v.Prerelease() != "" {
+			continue
+		}
+		tv.versions = append(tv.versions, v)
+		tv.deprecated[v.String()] = a.Deprecated
+	}
+	if len(tv.versions) == 0 {
+		return nil, errors.New("no Terraform versions available")
+	}
+
+	sort.Sort(sort.Reverse(version.Collection(tv.versions)))
+	return tv, nil
+}
+
+func parseTerraformVersionsFile(b []byte, source string) (*terraformVersions, error) {
+	f := &terraformVersionsFile{}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("parsing Terraform versions: %v", err)
+	}
+
+	return newTerraformVersions(f.Versions, source)
+}
+
+func listAdminTerraformVersions(ctx context.Context, config *setup.Config) (*terraformVersions, error) {
+	var available []terraformVersion
+	for page := 1; ; page++ {
+		versionsList, err := config.Client.Admin.TerraformVersions.List(ctx, &tfe.AdminTerraformVersionsListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range versionsList.Items {
+			if v.Beta {
+				continue
+			}
+			available = append(available, terraformVersion{Version: v.Version, Deprecated: v.Deprecated || !v.Enabled})
+		}
+
+		if versionsList.Pagination == nil || page >= versionsList.Pagination.TotalPages {
+			return newTerraformVersions(available, versionsSourceAdmin)
+		}
+	}
+}
+
+// getTerraformVersions returns the versions of the file if set, else those of the admin API,
+// falling back to the bundled versions when the API isn't available. They are shared by the scrapers of a refresh.
+func getTerraformVersions(ctx context.Context, config *setup.Config) (*terraformVersions, error) {
+	return sharedListing(ctx, terraformVersionsScraper, func(ctx context.Context) (*terraformVersions, error) {
+		if config.TerraformVersionsFile != "" {
+			b, err := os.ReadFile(config.TerraformVersionsFile)
+			if err != nil {
+				return nil, err
+			}
+			return parseTerraformVersionsFile(b, versionsSourceFile)
+		}
+
+		tv, err := listAdminTerraformVersions(ctx, config)
+		if errors.Is(err, tfe.ErrUnauthorized) || errors.Is(err, tfe.ErrResourceNotFound) {
+			return parseTerraformVersionsFile(bundledTerraformVersions, versionsSourceBundled)
+		}
+
+		return tv, err
+	})
+}
+
+func (tv *terraformVersions) latest() *version.Version {
+	return tv.versions[0]
+}
+
+// behind returns the number of versions and of minor versions newer than v.
+func (tv *terraformVersions) behind(v *version.Version) (versions, minors int) {
+	seenMinors := make(map[string]bool)
+	for _, a := range tv.versions {
+		if !a.GreaterThan(v) {
+			break
+		}
+		versions++
+
+		s := a.Segments()
+		minor := fmt.Sprintf("%d.%d", s[0], s[1])
+		if !seenMinors[minor] && !sameMinor(a, v) {
+			seenMinors[minor] = true
+			minors++
+		}
+	}
+
+	return versions, minors
+}
+
+// unsupported returns whether v is deprecated or older than the latest supportedMinors minor versions.
+func (tv *terraformVersions) unsupported(v *version.Version, supportedMinors int) bool {
+	if tv.deprecated[v.String()] {
+		return true
+	}
+	_, minors := tv.behind(v)
+
+	return supportedMinors > 0 && minors >= supportedMinors
+}
+
+func sameMinor(a, b *version.Version) bool {
+	sa, sb := a.Segments(), b.Segments()
+	return sa[0] == sb[0] && sa[1] == sb[1]
+}
+
+// workspaceVersion returns the Terraform version of the workspace, nil when it is unknown. Workspaces can use
+// a version constraint instead of a version, or a custom build or removed version missing from the available
+// versions, which can't be told apart from up-to-date versions and aren't reported.
+func (tv *terraformVersions) workspaceVersion(w *tfe.Workspace) *version.Version {
+	v, err := version.NewVersion(w.TerraformVersion)
+	if err != nil {
+		return nil
+	}
+	if _, ok := tv.deprecated[v.String()]; !ok {
+		return nil
+	}
+
+	return v
+}
+
+func getWorkspaceTerraformVersions(w *tfe.Workspace, organization string, tv *terraformVersions, supportedMinors int) []prometheus.Metric {
+	v := tv.workspaceVersion(w)
+	if v == nil {
+		return nil
+	}
+
+	versions, minors := tv.behind(v)
+	majors := tv.latest().Segments()[0] - v.Segments()[0]
+	if majors < 0 {
+		majors = 0
+	}
+	unsupported := 0.0
+	if tv.unsupported(v, supportedMinors) {
+		unsupported = 1
+	}
+
+	project := getProjectName(w)
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(WorkspacesTerraformVersionsBehind, prometheus.GaugeValue, float64(versions), w.ID, w.Name, organization, project, w.TerraformVersion),
+		prometheus.MustNewConstMetric(WorkspacesTerraformMajorVersionsBehind, prometheus.GaugeValue, float64(majors), w.ID, w.Name, organization, project, w.TerraformVersion),
+		prometheus.MustNewConstMetric(WorkspacesTerraformMinorVersionsBehind, prometheus.GaugeValue, float64(minors), w.ID, w.Name, organization, project, w.TerraformVersion),
+		prometheus.MustNewConstMetric(WorkspacesTerraformUnsupported, prometheus.GaugeValue, unsupported, w.ID, w.Name, organization, project, w.TerraformVersion),
+	}
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeTerraformVersions) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	tv, err := getTerraformVersions(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case ch <- prometheus.MustNewConstMetric(TerraformLatestVersion, prometheus.GaugeValue, 1, tv.latest().String(), tv.source):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			for _, w := range workspaces {
+				for _, m := range getWorkspaceTerraformVersions(w, name, tv, config.TerraformVersionsSupportedMinors) {
+					select {
+					case ch <- m:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const testTerraformVersions = `{"versions": [
+{"version": "1.3.0", "deprecated": true},
+{"version": "1.3.1", "deprecated": false},
+{"version": "1.5.7", "deprecated": false},
+{"version": "1.6.0", "deprecated": false},
+{"version": "1.9.5", "deprecated": false},
+{"version": "1.10.0", "deprecated": false},
+{"version": "1.10.1", "deprecated": false},
+{"version": "1.11.0-alpha1", "deprecated": false}
+]}`
+
+func TestScrapeTerraformVersions(t *testing.T) {
+	config := newDemoConfig(t)
+	config.TerraformVersionsSupportedMinors = 3
+	config.TerraformVersionsFile = filepath.Join(t.TempDir(), "versions.json")
+	if err := os.WriteFile(config.TerraformVersionsFile, []byte(testTerraformVersions), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	values := func(got []MetricResult) map[string]float64 {
+		v := make(map[string]float64, len(got))
+		for _, m := range got {
+			v[m.labels["name"]] = m.value
+		}
+		return v
+	}
+
+	convey.Convey("Latest version from the versions file, skipping pre-releases", t, func() {
+		got := scrapeMetrics(t, ScrapeTerraformVersions{}, config, TerraformLatestVersion)
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"version": "1.10.1", "source": "file"}, value: 1, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	convey.Convey("Versions behind the latest version", t, func() {
+		got := values(scrapeMetrics(t, ScrapeTerraformVersions{}, config, WorkspacesTerraformVersionsBehind))
+		convey.So(got, convey.ShouldResemble, map[string]float64{"network-prod": 2, "network-dev": 2, "app-frontend": 4, "sandbox": 6})
+	})
+
+	convey.Convey("Minor and major versions behind the latest version", t, func() {
+		minors := values(scrapeMetrics(t, ScrapeTerraformVersions{}, config, WorkspacesTerraformMinorVersionsBehind))
+		convey.So(minors, convey.ShouldResemble, map[string]float64{"network-prod": 1, "network-dev": 1, "app-frontend": 3, "sandbox": 4})
+		majors := values(scrapeMetrics(t, ScrapeTerraformVersions{}, config, WorkspacesTerraformMajorVersionsBehind))
+		convey.So(majors, convey.ShouldResemble, map[string]float64{"network-prod": 0, "network-dev": 0, "app-frontend": 0, "sandbox": 0})
+	})
+
+	convey.Convey("Deprecated or too old versions are unsupported", t, func() {
+		got := values(scrapeMetrics(t, ScrapeTerraformVersions{}, config, WorkspacesTerraformUnsupported))
+		convey.So(got, convey.ShouldResemble, map[string]float64{"network-prod": 0, "network-dev": 0, "app-frontend": 1, "sandbox": 1})
+	})
+
+	convey.Convey("Versions missing from the available versions are not reported", t, func() {
+		config := newDemoConfig(t)
+		config.TerraformVersionsFile = filepath.Join(t.TempDir(), "versions.json")
+		if err := os.WriteFile(config.TerraformVersionsFile, []byte(`{"versions": [{"version": "1.9.5"}, {"version": "1.10.0"}]}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		got := values(scrapeMetrics(t, ScrapeTerraformVersions{}, config, WorkspacesTerraformUnsupported))
+		convey.So(got, convey.ShouldResemble, map[string]float64{"network-prod": 0, "network-dev": 0})
+		convey.So(values(scrapeMetrics(t, ScrapeTerraformVersions{}, config, WorkspacesTerraformVersionsBehind)), convey.ShouldHaveLength, 2)
+	})
+
+	convey.Convey("Bundled versions are used without the admin API", t, func() {
+		config := newDemoConfig(t)
+		got := scrapeMetrics(t, ScrapeTerraformVersions{}, config, TerraformLatestVersion)
+		convey.So(got, convey.ShouldHaveLength, 1)
+		convey.So(got[0].labels["source"], convey.ShouldEqual, "bundled")
+	})
+}
@@ -0,0 +1,200 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// variables is the name of the Scraper, its metrics belong to the varsets and workspaces subsystems.
+	variablesScraper = "variables"
+	// varsets is the Metric subsystem of the variable sets.
+	varsetsSubsystem = "varsets"
+)
+
+// Metric descriptors. Variable values are never exported.
+var (
+	VarsetsInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, varsetsSubsystem, "info"),
+		"Information about existing variable sets",
+		[]string{"id", "name", "organization", "global", "priority"}, nil,
+	)
+	VarsetsWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, varsetsSubsystem, "workspaces"),
+		"Number of workspaces the variable set is applied to",
+		[]string{"id", "name", "organization"}, nil,
+	)
+	VarsetsProjects = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, varsetsSubsystem, "projects"),
+		"Number of projects the variable set is applied to",
+		[]string{"id", "name", "organization"}, nil,
+	)
+	VarsetsVariables = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, varsetsSubsystem, "variables"),
+		"Number of variables in the variable set",
+		[]string{"id", "name", "organization"}, nil,
+	)
+	WorkspacesVariables = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "variables"),
+		"Number of variables set on the workspace by category, sensitive and HCL flags",
+		[]string{"id", "name", "organization", "project", "category", "sensitive", "hcl"}, nil,
+	)
+)
+
+// ScrapeVariables scrapes an inventory of the variable sets and workspace variables.
+type ScrapeVariables struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeVariables{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeVariables) Name() string {
+	return variablesScraper
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeVariables) Help() string {
+	return "Scrape information from the Variable Sets and Workspace Variables APIs: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/variable-sets"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeVariables) Version() string {
+	return "v2"
+}
+
+func getVariableSetsListPage(ctx context.Context, page int, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	varsetsList, err := config.Client.VariableSets.List(ctx, organization, &tfe.VariableSetListOptions{
+		ListOptions: tfe.ListOptions{
+			PageSize:   pageSize,
+			PageNumber: page,
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+	}
+
+	for _, v := range varsetsList.Items {
+		for _, m := range []prometheus.Metric{
+			prometheus.MustNewConstMetric(VarsetsInfo, prometheus.GaugeValue, 1, v.ID, v.Name, organization, strconv.FormatBool(v.Global), strconv.FormatBool(v.Priority)),
+			prometheus.MustNewConstMetric(VarsetsWorkspaces, prometheus.GaugeValue, float64(len(v.Workspaces)), v.ID, v.Name, organization),
+			prometheus.MustNewConstMetric(VarsetsProjects, prometheus.GaugeValue, float64(len(v.Projects)), v.ID, v.Name, organization),
+			prometheus.MustNewConstMetric(VarsetsVariables, prometheus.GaugeValue, float64(len(v.Variables)), v.ID, v.Name, organization),
+		} {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// variableCountKey identifies a tf_workspaces_variables series of a workspace.
+type variableCountKey struct {
+	category       tfe.CategoryType
+	sensitive, hcl bool
+}
+
+func getWorkspaceVariables(ctx context.Context, w *tfe.Workspace, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
+	counts := make(map[variableCountKey]int)
+	for page := 1; ; page++ {
+		variablesList, err := config.Client.Variables.List(ctx, w.ID, &tfe.VariableListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("%v, (organization=%s, workspace=%s, page=%d)", err, organization, w.Name, page)
+		}
+
+		for _, v := range variablesList.Items {
+			counts[variableCountKey{category: v.Category, sensitive: v.Sensitive, hcl: v.HCL}]++
+		}
+
+		if variablesList.Pagination == nil || page >= variablesList.Pagination.TotalPages {
+			break
+		}
+	}
+
+	for k, count := range counts {
+		select {
+		case ch <- prometheus.MustNewConstMetric(
+			WorkspacesVariables,
+			prometheus.GaugeValue,
+			float64(count),
+			w.ID,
+			w.Name,
+			organization,
+			getProjectName(w),
+			string(k.category),
+			strconv.FormatBool(k.sensitive),
+			strconv.FormatBool(k.hcl),
+		):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeVariables) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentWorkspaceFetches)
+
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			varsetsList, err := config.Client.VariableSets.List(ctx, name, &tfe.VariableSetListOptions{
+				ListOptions: tfe.ListOptions{
+					PageSize: pageSize,
+				}})
+
+			if err != nil {
+				return fmt.Errorf("%v, organization=%s", err, name)
+			}
+
+			for i := 1; i <= varsetsList.Pagination.TotalPages; i++ {
+				if err := getVariableSetsListPage(ctx, i, name, config, ch); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		g.Go(func() error {
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			wsErrs, wsCtx := errgroup.WithContext(ctx)
+			for _, w := range workspaces {
+				w := w
+				wsErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+					return getWorkspaceVariables(wsCtx, w, name, config, ch)
+				})
+			}
+			return wsErrs.Wait()
+		})
+	}
+
+	return g.Wait()
+}
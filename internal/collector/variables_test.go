@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeVariables(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Variable sets", t, func() {
+		labels := labelMap{"id": "varset-aws", "name": "aws-credentials", "organization": "demo-org"}
+		convey.So(scrapeMetrics(t, ScrapeVariables{}, config, VarsetsWorkspaces), convey.ShouldContain, MetricResult{labels: labels, value: 2, metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeVariables{}, config, VarsetsProjects), convey.ShouldContain, MetricResult{labels: labels, value: 1, metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeVariables{}, config, VarsetsVariables), convey.ShouldContain, MetricResult{labels: labels, value: 2, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Workspace variables by category and flags", t, func() {
+		got := scrapeMetrics(t, ScrapeVariables{}, config, WorkspacesVariables)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform",
+			"category": "terraform", "sensitive": "false", "hcl": "true",
+		}, value: 2, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform",
+			"category": "env", "sensitive": "true", "hcl": "false",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+}
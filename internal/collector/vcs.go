@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/setup"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// vcs is the Metric subsystem we use.
+	vcsSubsystem = "vcs"
+)
+
+// Metric descriptors.
+var (
+	VCSOAuthClientInfo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, vcsSubsystem, "oauth_client_info"),
+		"Information about the OAuth clients (VCS providers) of the organization",
+		[]string{"id", "name", "organization", "service_provider", "service_provider_name", "http_url", "organization_scoped", "created_at"}, nil,
+	)
+	VCSOAuthTokenWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, vcsSubsystem, "oauth_token_workspaces"),
+		"Number of workspaces connected to their VCS repository through the OAuth token",
+		[]string{"id", "organization", "oauth_client_id", "oauth_client_name", "service_provider"}, nil,
+	)
+	VCSGitHubAppInstallationWorkspaces = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, vcsSubsystem, "github_app_installation_workspaces"),
+		"Number of workspaces connected to their VCS repository through the GitHub App installation",
+		[]string{"id", "organization"}, nil,
+	)
+)
+
+// ScrapeVCS scrapes the VCS providers of the organizations and counts the workspaces connected through them,
+// from the workspaces listed for the workspaces scraper.
+type ScrapeVCS struct{}
+
+func init() {
+	Scrapers = append(Scrapers, ScrapeVCS{})
+}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeVCS) Name() string {
+	return vcsSubsystem
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeVCS) Help() string {
+	return "Scrape information from the OAuth Clients API: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/oauth-clients"
+}
+
+// Version of Terraform Cloud/Enterprise API from which scraper is available.
+func (ScrapeVCS) Version() string {
+	return "v2"
+}
+
+func listOAuthClients(ctx context.Context, organization string, config *setup.Config) ([]*tfe.OAuthClient, error) {
+	var clients []*tfe.OAuthClient
+	for page := 1; ; page++ {
+		clientsList, err := config.Client.OAuthClients.List(ctx, organization, &tfe.OAuthClientListOptions{
+			ListOptions: tfe.ListOptions{
+				PageSize:   pageSize,
+				PageNumber: page,
+			},
+			Include: []tfe.OAuthClientIncludeOpt{tfe.OauthClientOauthTokens},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+		}
+
+		clients = append(clients, clientsList.Items...)
+		if clientsList.Pagination == nil || page >= clientsList.Pagination.TotalPages {
+			return clients, nil
+		}
+	}
+}
+
+// getOAuthClientName returns the name of the OAuth client, which is optional.
+func getOAuthClientName(c *tfe.OAuthClient) string {
+	if c.Name == nil {
+		return ""
+	}
+
+	return *c.Name
+}
+
+// getOrganizationScoped returns whether the OAuth client is available to every project, which older
+// Terraform Enterprise versions don't report.
+func getOrganizationScoped(c *tfe.OAuthClient) string {
+	if c.OrganizationScoped == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*c.OrganizationScoped)
+}
+
+// getVCSConnections returns the metrics of the OAuth clients of the organization, and the number of workspaces
+// connected through each of their tokens and each GitHub App installation.
+func getVCSConnections(clients []*tfe.OAuthClient, workspaces []*tfe.Workspace, organization string) []prometheus.Metric {
+	tokens := make(map[string]int)
+	installations := make(map[string]int)
+	for _, w := range workspaces {
+		switch {
+		case w.VCSRepo == nil:
+		case w.VCSRepo.OAuthTokenID != "":
+			tokens[w.VCSRepo.OAuthTokenID]++
+		case w.VCSRepo.GHAInstallationID != "":
+			installations[w.VCSRepo.GHAInstallationID]++
+		}
+	}
+
+	var metrics []prometheus.Metric
+	for _, c := range clients {
+		name := getOAuthClientName(c)
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			VCSOAuthClientInfo,
+			prometheus.GaugeValue,
+			1,
+			c.ID,
+			name,
+			organization,
+			string(c.ServiceProvider),
+			c.ServiceProviderName,
+			c.HTTPURL,
+			getOrganizationScoped(c),
+			c.CreatedAt.String(),
+		))
+		// Tokens without workspaces are reported too, they are the ones left to clean up after a migration.
+		for _, t := range c.OAuthTokens {
+			metrics = append(metrics, prometheus.MustNewConstMetric(VCSOAuthTokenWorkspaces, prometheus.GaugeValue, float64(tokens[t.ID]), t.ID, organization, c.ID, name, string(c.ServiceProvider)))
+			delete(tokens, t.ID)
+		}
+	}
+	// Workspaces can be connected through a token the exporter's token can't see the client of.
+	for id, count := range tokens {
+		metrics = append(metrics, prometheus.MustNewConstMetric(VCSOAuthTokenWorkspaces, prometheus.GaugeValue, float64(count), id, organization, "", "", ""))
+	}
+	for id, count := range installations {
+		metrics = append(metrics, prometheus.MustNewConstMetric(VCSGitHubAppInstallationWorkspaces, prometheus.GaugeValue, float64(count), id, organization))
+	}
+
+	return metrics
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+func (ScrapeVCS) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			clients, err := listOAuthClients(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			workspaces, err := listWorkspaces(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			for _, m := range getVCSConnections(clients, workspaces, name) {
+				select {
+				case ch <- m:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
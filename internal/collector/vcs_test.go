@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/hashicorp/go-tfe"
+	"github.com/nicolaka/tfbi/internal/fakeapi"
+	"github.com/nicolaka/tfbi/internal/setup"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeVCS(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("OAuth clients", t, func() {
+		got := scrapeMetrics(t, ScrapeVCS{}, config, VCSOAuthClientInfo)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "oc-gitlab", "name": "GitLab", "organization": "demo-org", "service_provider": "gitlab_hosted",
+			"service_provider_name": "GitLab.com", "http_url": "https://gitlab.com", "organization_scoped": "false",
+			"created_at": "2023-06-01 09:30:00 +0000 UTC",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Workspaces by OAuth token, including unused tokens", t, func() {
+		got := scrapeMetrics(t, ScrapeVCS{}, config, VCSOAuthTokenWorkspaces)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ot-github", "organization": "demo-org", "oauth_client_id": "oc-github", "oauth_client_name": "GitHub", "service_provider": "github",
+		}, value: 2, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ot-gitlab", "organization": "demo-org", "oauth_client_id": "oc-gitlab", "oauth_client_name": "GitLab", "service_provider": "gitlab_hosted",
+		}, value: 0, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Workspaces by GitHub App installation and unknown OAuth token", t, func() {
+		workspaces := []*tfe.Workspace{
+			{VCSRepo: &tfe.VCSRepo{GHAInstallationID: "ghain-1"}},
+			{VCSRepo: &tfe.VCSRepo{GHAInstallationID: "ghain-1"}},
+			{VCSRepo: &tfe.VCSRepo{OAuthTokenID: "ot-other"}},
+			{},
+		}
+
+		var installations, tokens int
+		for _, m := range getVCSConnections(nil, workspaces, "demo-org") {
+			got := readMetric(m)
+			switch m.Desc() {
+			case VCSGitHubAppInstallationWorkspaces:
+				installations++
+				convey.So(got, convey.ShouldResemble, MetricResult{labels: labelMap{"id": "ghain-1", "organization": "demo-org"}, value: 2, metricType: dto.MetricType_GAUGE})
+			case VCSOAuthTokenWorkspaces:
+				tokens++
+				convey.So(got, convey.ShouldResemble, MetricResult{labels: labelMap{
+					"id": "ot-other", "organization": "demo-org", "oauth_client_id": "", "oauth_client_name": "", "service_provider": "",
+				}, value: 1, metricType: dto.MetricType_GAUGE})
+			}
+		}
+		convey.So(installations, convey.ShouldEqual, 1)
+		convey.So(tokens, convey.ShouldEqual, 1)
+	})
+}
+
+func TestVCSSharesWorkspaces(t *testing.T) {
+	dataset, err := fakeapi.Demo()
+	if err != nil {
+		t.Fatalf("error loading demo dataset: %s", err)
+	}
+	var listings atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/organizations/demo-org/workspaces" {
+			listings.Add(1)
+		}
+		fakeapi.NewServer(dataset).ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := tfe.NewClient(&tfe.Config{Address: server.URL, Token: "test"})
+	if err != nil {
+		t.Fatalf("error creating a stub api client: %s", err)
+	}
+	e := New(setup.Config{Client: *client, CLI: setup.CLI{Organizations: []string{"demo-org"}}, Logger: log.NewNopLogger()}, NewMetrics())
+	e.scrapers = []Scraper{ScrapeWorkspaces{}, ScrapeVCS{}, ScrapeGovernance{}}
+
+	convey.Convey("Workspaces are listed once per refresh for the workspaces, vcs and governance collectors", t, func() {
+		e.Refresh(context.Background())
+		convey.So(readMetric(e.metrics.Error).value, convey.ShouldEqual, 0)
+		convey.So(e.results[vcsSubsystem].metrics, convey.ShouldNotBeEmpty)
+		convey.So(listings.Load(), convey.ShouldEqual, 1)
+
+		e.Refresh(context.Background())
+		convey.So(listings.Load(), convey.ShouldEqual, 2)
+	})
+}
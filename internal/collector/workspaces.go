@@ -1,15 +1,24 @@
 package collector
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
 	"strconv"
+	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/go-kit/kit/log/level"
 	"github.com/nicolaka/tfbi/internal/setup"
 
 	tfe "github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/jsonapi"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -19,6 +28,8 @@ const (
 	workspacesSubsystem = "workspaces"
 	// Selecting a page size of 100 to minimize number of requests for larger  workspace deployments
 	pageSize = 100
+	// maxConcurrentWorkspaceFetches bounds the requests a collector makes at once for the workspaces of an organization.
+	maxConcurrentWorkspaceFetches = 20
 )
 
 // Metric descriptors.
@@ -26,7 +37,77 @@ var (
 	WorkspacesInfo = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, workspacesSubsystem, "info"),
 		"Information about existing workspaces",
-		[]string{"id", "name", "organization", "terraform_version", "created_at", "environment", "current_run", "current_run_status", "current_run_created_at", "project", "assessments_enabled", "description", "resource_count", "policy_check_failures", "run_failures", "runs_count", "rum_count"}, nil,
+		[]string{"id", "name", "organization", "terraform_version", "created_at", "environment", "current_run", "current_run_status", "current_run_created_at", "project", "assessments_enabled", "description", "execution_mode", "agent_pool"}, nil,
+	)
+	WorkspacesVCSRepo = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "vcs_repo_info"),
+		"VCS repository of the workspace with the OAuth token or GitHub App installation it is connected through, and its VCS settings",
+		[]string{"id", "name", "organization", "project", "service_provider", "repository", "branch", "oauth_token_id", "github_app_installation_id", "working_directory", "speculative_enabled", "auto_apply"}, nil,
+	)
+	WorkspacesSettings = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "settings"),
+		"Boolean settings of the workspace (1 for enabled, 0 otherwise)",
+		[]string{"id", "name", "organization", "project", "setting"}, nil,
+	)
+	WorkspacesSettingsValue = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "settings_value"),
+		"Enumerated settings of the workspace, execution_mode and source (1 for the current value, 0 for the other values)",
+		[]string{"id", "name", "organization", "project", "setting", "value"}, nil,
+	)
+	WorkspacesResources = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "resources"),
+		"Number of resources managed by the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesRUM = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "rum"),
+		"Number of billable Resources Under Management (RUM) in the workspace's current state version",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesRuns = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "runs_total"),
+		"Total number of runs of the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesRunFailures = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "run_failures_total"),
+		"Total number of failed runs of the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesPolicyCheckFailures = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "policy_check_failures_total"),
+		"Total number of failed policy checks of the workspace",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesLastRun = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "last_run_timestamp_seconds"),
+		"Time the workspace's current run was created",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesLastStateChange = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "last_state_change_timestamp_seconds"),
+		"Time the workspace's current state version was created",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesLastUpdate = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "last_update_timestamp_seconds"),
+		"Time the workspace was last updated",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesStale = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "stale"),
+		"Whether the workspace had no run, state change or update within the stale threshold (1 for stale, 0 otherwise)",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesLocked = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "locked"),
+		"Whether the workspace is locked (1 for locked, 0 otherwise)",
+		[]string{"id", "name", "organization", "project"}, nil,
+	)
+	WorkspacesLockedSince = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, workspacesSubsystem, "locked_since_timestamp_seconds"),
+		"Time the workspace was locked by its lock holder, whose type is run, user or team. Run locks date from the creation of the run, other locks from when the exporter first saw them",
+		[]string{"id", "name", "organization", "project", "locked_by_type"}, nil,
 	)
 )
 
@@ -52,8 +133,196 @@ func (ScrapeWorkspaces) Version() string {
 	return "v2"
 }
 
-func getWorkspacesListPage(ctx context.Context, page int, organization string, config *setup.Config, ch chan<- prometheus.Metric) error {
-	workspacesList, err := config.Client.Workspaces.List(ctx, organization, &tfe.WorkspaceListOptions{
+func getWorkspacesMetrics(ctx context.Context, workspaces []*tfe.Workspace, sources map[string]string, organization string, locks *workspaceLocks, config *setup.Config, ch chan<- prometheus.Metric) error {
+	now := time.Now()
+	for _, w := range workspaces {
+		project := getProjectName(w)
+		activity := getWorkspaceActivity(w)
+		metrics := []prometheus.Metric{
+			prometheus.MustNewConstMetric(
+				WorkspacesInfo,
+				prometheus.GaugeValue,
+				1,
+				w.ID,
+				w.Name,
+				organization,
+				w.TerraformVersion,
+				w.CreatedAt.String(),
+				w.Environment,
+				getCurrentRunID(w.CurrentRun),
+				getCurrentRunStatus(w.CurrentRun),
+				getCurrentRunCreatedAt(w.CurrentRun),
+				project,
+				strconv.FormatBool(w.AssessmentsEnabled),
+				w.Description,
+				w.ExecutionMode,
+				getAgentPoolID(w.AgentPool),
+			),
+			prometheus.MustNewConstMetric(WorkspacesResources, prometheus.GaugeValue, float64(w.ResourceCount), w.ID, w.Name, organization, project),
+			prometheus.MustNewConstMetric(WorkspacesRUM, prometheus.GaugeValue, float64(getCurrentRUM(w.CurrentStateVersion)), w.ID, w.Name, organization, project),
+			prometheus.MustNewConstMetric(WorkspacesRuns, prometheus.CounterValue, float64(w.RunsCount), w.ID, w.Name, organization, project),
+			prometheus.MustNewConstMetric(WorkspacesRunFailures, prometheus.CounterValue, float64(w.RunFailures), w.ID, w.Name, organization, project),
+			prometheus.MustNewConstMetric(WorkspacesPolicyCheckFailures, prometheus.CounterValue, float64(w.PolicyCheckFailures), w.ID, w.Name, organization, project),
+			prometheus.MustNewConstMetric(WorkspacesLastUpdate, prometheus.GaugeValue, float64(w.UpdatedAt.Unix()), w.ID, w.Name, organization, project),
+			prometheus.MustNewConstMetric(WorkspacesStale, prometheus.GaugeValue, getWorkspaceStale(w, activity, now, config.WorkspacesStaleAfter), w.ID, w.Name, organization, project),
+		}
+		metrics = append(metrics, getWorkspaceSettings(w, sources[w.ID], organization, project)...)
+		metrics = append(metrics, getWorkspaceLock(w, locks.observe(w, now), organization, project)...)
+		if w.VCSRepo != nil {
+			metrics = append(metrics, prometheus.MustNewConstMetric(
+				WorkspacesVCSRepo,
+				prometheus.GaugeValue,
+				1,
+				w.ID,
+				w.Name,
+				organization,
+				project,
+				w.VCSRepo.ServiceProvider,
+				w.VCSRepo.Identifier,
+				w.VCSRepo.Branch,
+				w.VCSRepo.OAuthTokenID,
+				w.VCSRepo.GHAInstallationID,
+				w.WorkingDirectory,
+				strconv.FormatBool(w.SpeculativeEnabled),
+				strconv.FormatBool(w.AutoApply),
+			))
+		}
+		if !activity.lastRun.IsZero() {
+			metrics = append(metrics, prometheus.MustNewConstMetric(WorkspacesLastRun, prometheus.GaugeValue, float64(activity.lastRun.Unix()), w.ID, w.Name, organization, project))
+		}
+		if !activity.lastStateChange.IsZero() {
+			metrics = append(metrics, prometheus.MustNewConstMetric(WorkspacesLastStateChange, prometheus.GaugeValue, float64(activity.lastStateChange.Unix()), w.ID, w.Name, organization, project))
+		}
+
+		for _, m := range metrics {
+			select {
+			case ch <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// workspacesState holds the locks seen by the last scrape by workspace id, to tell since when they are held.
+type workspacesState struct {
+	Locks map[string]workspaceLock `json:"locks"`
+}
+
+// ScrapeState collects data from Terraform API and sends it over channel as prometheus metric,
+// given the locks seen by the last scrape.
+func (ScrapeWorkspaces) ScrapeState(ctx context.Context, config *setup.Config, state json.RawMessage, ch chan<- prometheus.Metric) (json.RawMessage, error) {
+	s := &workspacesState{}
+	if state != nil {
+		if err := json.Unmarshal(state, s); err != nil {
+			level.Warn(config.Logger).Log("msg", "Ignoring invalid workspaces state", "err", err)
+			s = &workspacesState{}
+		}
+	}
+	locks := newWorkspaceLocks(s.Locks)
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range config.Organizations {
+		name := name
+		g.Go(func() error {
+			l, err := getWorkspacesListing(ctx, name, config)
+			if err != nil {
+				return err
+			}
+
+			return getWorkspacesMetrics(ctx, l.workspaces, l.sources, name, locks, config, ch)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&workspacesState{Locks: locks.current})
+}
+
+// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
+// Without the state kept by the Exporter, locks other than run locks are reported as held since this scrape.
+func (s ScrapeWorkspaces) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
+	_, err := s.ScrapeState(ctx, config, nil, ch)
+	return err
+}
+
+// listWorkspaces returns every workspace of the organization, with its project, current run, current state version
+// and lock holder included. The workspaces are listed once per refresh, for the workspaces scraper and the scrapers
+// that count them or query per-workspace endpoints.
+func listWorkspaces(ctx context.Context, organization string, config *setup.Config) ([]*tfe.Workspace, error) {
+	l, err := getWorkspacesListing(ctx, organization, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.workspaces, nil
+}
+
+// workspacesListing holds the workspaces of an organization and the sources they were created from by workspace id.
+type workspacesListing struct {
+	workspaces []*tfe.Workspace
+	sources    map[string]string
+}
+
+func getWorkspacesListing(ctx context.Context, organization string, config *setup.Config) (*workspacesListing, error) {
+	return sharedListing(ctx, "workspaces/"+organization, func(ctx context.Context) (*workspacesListing, error) {
+		first, err := readWorkspacesPage(ctx, 1, organization, config)
+		if err != nil {
+			return nil, err
+		}
+		pages := []*workspacesPage{first}
+		if first.pagination != nil && first.pagination.TotalPages > 1 {
+			const maxConcurrentPageFetches = 100 // tune as needed
+			pages = append(pages, make([]*workspacesPage, first.pagination.TotalPages-1)...)
+			sem := make(chan struct{}, maxConcurrentPageFetches)
+
+			pageErrs, pageCtx := errgroup.WithContext(ctx)
+			for i := 2; i <= len(pages); i++ {
+				i := i
+				pageErrs.Go(func() error {
+					sem <- struct{}{}        // acquire
+					defer func() { <-sem }() // release
+
+					page, err := readWorkspacesPage(pageCtx, i, organization, config)
+					if err != nil {
+						return err
+					}
+					pages[i-1] = page
+					return nil
+				})
+			}
+			if err := pageErrs.Wait(); err != nil {
+				return nil, err
+			}
+		}
+
+		l := &workspacesListing{sources: make(map[string]string)}
+		for _, page := range pages {
+			l.workspaces = append(l.workspaces, page.items...)
+			for id, source := range page.sources {
+				l.sources[id] = source
+			}
+		}
+
+		return l, nil
+	})
+}
+
+// workspacesPage is a page of workspaces with their sources by workspace id.
+type workspacesPage struct {
+	items      []*tfe.Workspace
+	sources    map[string]string
+	pagination *tfe.Pagination
+}
+
+// readWorkspacesPage returns a page of workspaces. go-tfe doesn't support the source of the workspaces,
+// so it is decoded from the same response rather than requested again.
+func readWorkspacesPage(ctx context.Context, page int, organization string, config *setup.Config) (*workspacesPage, error) {
+	req, err := config.Client.NewRequest("GET", fmt.Sprintf("organizations/%s/workspaces", url.PathEscape(organization)), &tfe.WorkspaceListOptions{
 		ListOptions: tfe.ListOptions{
 			PageSize:   pageSize,
 			PageNumber: page,
@@ -64,78 +333,237 @@ func getWorkspacesListPage(ctx context.Context, page int, organization string, c
 			// go-tfe/issues/1020
 			//"organization",
 			"current_state_version",
+			"locked_by",
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
-	}
-
-	for _, w := range workspacesList.Items {
-		select {
-		case ch <- prometheus.MustNewConstMetric(
-			WorkspacesInfo,
-			prometheus.GaugeValue,
-			1,
-			w.ID,
-			w.Name,
-			organization,
-			w.TerraformVersion,
-			w.CreatedAt.String(),
-			w.Environment,
-			getCurrentRunID(w.CurrentRun),
-			getCurrentRunStatus(w.CurrentRun),
-			getCurrentRunCreatedAt(w.CurrentRun),
-			w.Project.Name,
-			strconv.FormatBool(w.AssessmentsEnabled),
-			w.Description,
-			strconv.Itoa(w.ResourceCount),
-			strconv.Itoa(w.PolicyCheckFailures),
-			strconv.Itoa(w.RunFailures),
-			strconv.Itoa(w.RunsCount),
-			getCurrentRUM(w.CurrentStateVersion),
-		):
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		return nil, err
+	}
 
+	body := &bytes.Buffer{}
+	if err := req.Do(ctx, body); err != nil {
+		return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
 	}
 
-	return nil
+	p, err := decodeWorkspacesPage(body.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%v, (organization=%s, page=%d)", err, organization, page)
+	}
+
+	return p, nil
 }
 
-// Scrape collects data from Terraform API and sends it over channel as prometheus metric.
-func (ScrapeWorkspaces) Scrape(ctx context.Context, config *setup.Config, ch chan<- prometheus.Metric) error {
-	const maxConcurrentPageFetches = 100 // tune as needed
-	g, ctx := errgroup.WithContext(ctx)
-	sem := make(chan struct{}, maxConcurrentPageFetches)
+func decodeWorkspacesPage(b []byte) (*workspacesPage, error) {
+	items, err := jsonapi.UnmarshalManyPayload(bytes.NewReader(b), reflect.TypeOf(&tfe.Workspace{}))
+	if err != nil {
+		return nil, err
+	}
 
-	for _, name := range config.Organizations {
-		name := name
-		g.Go(func() error {
-			workspacesList, err := config.Client.Workspaces.List(ctx, name, &tfe.WorkspaceListOptions{
-				ListOptions: tfe.ListOptions{
-					PageSize: pageSize,
-				},
-			})
+	var raw struct {
+		Data []struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				Source string `json:"source"`
+			} `json:"attributes"`
+		} `json:"data"`
+		Meta struct {
+			Pagination *tfe.Pagination `json:"pagination"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
 
-			if err != nil {
-				return fmt.Errorf("%v, organization=%s", err, name)
-			}
+	p := &workspacesPage{sources: make(map[string]string, len(raw.Data)), pagination: raw.Meta.Pagination}
+	for _, item := range items {
+		p.items = append(p.items, item.(*tfe.Workspace))
+	}
+	for _, d := range raw.Data {
+		if d.Attributes.Source != "" {
+			p.sources[d.ID] = d.Attributes.Source
+		}
+	}
 
-			pageErrs, pageCtx := errgroup.WithContext(ctx)
-			for i := 1; i <= workspacesList.Pagination.TotalPages; i++ {
-				i := i
-				pageErrs.Go(func() error {
-					sem <- struct{}{} // acquire
-					defer func() { <-sem }() // release
-					return getWorkspacesListPage(pageCtx, i, name, config, ch)
-				})
+	return p, nil
+}
+
+// Known values of the enumerated workspace settings, reported as 0 when not current.
+var (
+	workspaceExecutionModes = []string{"remote", "local", "agent"}
+	workspaceSources        = []string{"tfe-ui", "tfe-api", "tfe-module", "terraform"}
+)
+
+// getWorkspaceSettings returns the tf_workspaces_settings and tf_workspaces_settings_value metrics of the workspace.
+// The source is left out when it is unknown.
+func getWorkspaceSettings(w *tfe.Workspace, source, organization, project string) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	for _, s := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"auto_apply", w.AutoApply},
+		{"locked", w.Locked},
+		{"speculative_enabled", w.SpeculativeEnabled},
+		{"file_triggers_enabled", w.FileTriggersEnabled},
+		{"queue_all_runs", w.QueueAllRuns},
+		{"global_remote_state", w.GlobalRemoteState},
+		{"allow_destroy_plan", w.AllowDestroyPlan},
+		{"structured_run_output_enabled", w.StructuredRunOutputEnabled},
+	} {
+		value := 0.0
+		if s.enabled {
+			value = 1
+		}
+		metrics = append(metrics, prometheus.MustNewConstMetric(WorkspacesSettings, prometheus.GaugeValue, value, w.ID, w.Name, organization, project, s.name))
+	}
+
+	for _, s := range []struct {
+		name, current string
+		known         []string
+	}{
+		{"execution_mode", w.ExecutionMode, workspaceExecutionModes},
+		{"source", source, workspaceSources},
+	} {
+		if s.current == "" {
+			continue
+		}
+		values := s.known
+		if !slices.Contains(values, s.current) {
+			values = append(append([]string(nil), values...), s.current)
+		}
+		for _, v := range values {
+			current := 0.0
+			if v == s.current {
+				current = 1
 			}
-			return pageErrs.Wait()
-		})
+			metrics = append(metrics, prometheus.MustNewConstMetric(WorkspacesSettingsValue, prometheus.GaugeValue, current, w.ID, w.Name, organization, project, s.name, v))
+		}
+	}
+
+	return metrics
+}
+
+// getProjectName returns the name of the workspace's project, if it was included in the response.
+func getProjectName(w *tfe.Workspace) string {
+	if w.Project == nil {
+		return ""
+	}
+
+	return w.Project.Name
+}
+
+// getAgentPoolID returns the id of the agent pool the workspace runs on, empty unless it uses agent execution mode.
+func getAgentPoolID(p *tfe.AgentPool) string {
+	if p == nil {
+		return ""
+	}
+
+	return p.ID
+}
+
+// workspaceActivity holds the times of the last activities of a workspace, zero when unknown.
+type workspaceActivity struct {
+	lastRun, lastStateChange time.Time
+}
+
+func getWorkspaceActivity(w *tfe.Workspace) workspaceActivity {
+	var a workspaceActivity
+	if w.CurrentRun != nil {
+		a.lastRun = w.CurrentRun.CreatedAt
+	}
+	if w.CurrentStateVersion != nil {
+		a.lastStateChange = w.CurrentStateVersion.CreatedAt
+	}
+
+	return a
+}
+
+// getWorkspaceStale returns 1 if the workspace had no run, state change or update within staleAfter, 0 otherwise.
+// A zero staleAfter disables the detection.
+func getWorkspaceStale(w *tfe.Workspace, a workspaceActivity, now time.Time, staleAfter time.Duration) float64 {
+	if staleAfter <= 0 {
+		return 0
+	}
+
+	threshold := now.Add(-staleAfter)
+	for _, t := range []time.Time{a.lastRun, a.lastStateChange, w.UpdatedAt, w.CreatedAt} {
+		if t.After(threshold) {
+			return 0
+		}
+	}
+
+	return 1
+}
+
+// workspaceLock is the lock holder of a workspace and since when it holds the lock.
+type workspaceLock struct {
+	HolderType string    `json:"holder_type"`
+	HolderID   string    `json:"holder_id"`
+	Since      time.Time `json:"since"`
+}
+
+// workspaceLocks records the locks seen by a scrape, whose pages are read concurrently.
+type workspaceLocks struct {
+	mu       sync.Mutex
+	previous map[string]workspaceLock
+	current  map[string]workspaceLock
+}
+
+func newWorkspaceLocks(previous map[string]workspaceLock) *workspaceLocks {
+	return &workspaceLocks{previous: previous, current: make(map[string]workspaceLock)}
+}
+
+// getLockHolder returns the type and id of the holder of the workspace lock, empty when unknown.
+func getLockHolder(l *tfe.LockedByChoice) (holderType, holderID string) {
+	switch {
+	case l == nil:
+		return "", ""
+	case l.Run != nil:
+		return "run", l.Run.ID
+	case l.User != nil:
+		return "user", l.User.ID
+	case l.Team != nil:
+		return "team", l.Team.ID
+	}
+
+	return "", ""
+}
+
+// observe records the lock of the workspace, nil if it isn't locked. A lock already seen by the last scrape
+// keeps its time, a run lock dates from the creation of the run and other locks from now.
+func (l *workspaceLocks) observe(w *tfe.Workspace, now time.Time) *workspaceLock {
+	if !w.Locked {
+		return nil
+	}
+
+	holderType, holderID := getLockHolder(w.LockedBy)
+	lock := workspaceLock{HolderType: holderType, HolderID: holderID, Since: now}
+	if holderType == "run" && !w.LockedBy.Run.CreatedAt.IsZero() && w.LockedBy.Run.CreatedAt.Before(now) {
+		lock.Since = w.LockedBy.Run.CreatedAt
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if prev, ok := l.previous[w.ID]; ok && prev.HolderType == holderType && prev.HolderID == holderID && prev.Since.Before(lock.Since) {
+		lock.Since = prev.Since
+	}
+	l.current[w.ID] = lock
+
+	return &lock
+}
+
+// getWorkspaceLock returns the tf_workspaces_locked and tf_workspaces_locked_since_timestamp_seconds metrics of the workspace.
+func getWorkspaceLock(w *tfe.Workspace, lock *workspaceLock, organization, project string) []prometheus.Metric {
+	if lock == nil {
+		return []prometheus.Metric{
+			prometheus.MustNewConstMetric(WorkspacesLocked, prometheus.GaugeValue, 0, w.ID, w.Name, organization, project),
+		}
 	}
 
-	return g.Wait()
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(WorkspacesLocked, prometheus.GaugeValue, 1, w.ID, w.Name, organization, project),
+		prometheus.MustNewConstMetric(WorkspacesLockedSince, prometheus.GaugeValue, float64(lock.Since.Unix()), w.ID, w.Name, organization, project, lock.HolderType),
+	}
 }
 
 func getCurrentRunID(r *tfe.Run) string {
@@ -163,15 +591,10 @@ func getCurrentRunCreatedAt(r *tfe.Run) string {
 }
 
 // Getting current Billible Resources Under Management (RUM)
-func getCurrentRUM(s *tfe.StateVersion) string {
-
-	if s == nil {
-		return "0"
-	}
-
-	if s.BillableRUMCount == nil {
-		return "0"
+func getCurrentRUM(s *tfe.StateVersion) uint32 {
+	if s == nil || s.BillableRUMCount == nil {
+		return 0
 	}
 
-	return strconv.Itoa(int(*s.BillableRUMCount))
+	return *s.BillableRUMCount
 }
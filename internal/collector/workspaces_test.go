@@ -0,0 +1,195 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeWorkspaces(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Workspace info", t, func() {
+		got := scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesInfo)
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-sandbox", "name": "sandbox", "organization": "demo-org", "terraform_version": "1.3.0",
+			"created_at": "2024-06-01 12:00:00 +0000 UTC", "environment": "default", "current_run": "na",
+			"current_run_status": "na", "current_run_created_at": "na", "project": "Default Project",
+			"assessments_enabled": "false", "description": "", "execution_mode": "local", "agent_pool": "",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Workspace agent pool", t, func() {
+		got := scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesInfo)
+		for _, m := range got {
+			if m.labels["id"] == "ws-network-prod" {
+				convey.So(m.labels["execution_mode"], convey.ShouldEqual, "agent")
+				convey.So(m.labels["agent_pool"], convey.ShouldEqual, "apool-datacenter")
+			}
+		}
+	})
+
+	convey.Convey("Workspace VCS repository", t, func() {
+		got := scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesVCSRepo)
+		convey.So(got, convey.ShouldHaveLength, 2)
+		convey.So(got, convey.ShouldContain, MetricResult{labels: labelMap{
+			"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform",
+			"service_provider": "github", "repository": "demo-org/network", "branch": "main", "oauth_token_id": "ot-github",
+			"github_app_installation_id": "", "working_directory": "envs/prod", "speculative_enabled": "true", "auto_apply": "false",
+		}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	convey.Convey("Workspace settings", t, func() {
+		settings := make(map[string]map[string]float64)
+		for _, m := range scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesSettings) {
+			if settings[m.labels["name"]] == nil {
+				settings[m.labels["name"]] = make(map[string]float64)
+			}
+			settings[m.labels["name"]][m.labels["setting"]] = m.value
+		}
+		convey.So(settings, convey.ShouldHaveLength, 4)
+		convey.So(settings["network-dev"], convey.ShouldResemble, map[string]float64{
+			"auto_apply": 0, "locked": 1, "speculative_enabled": 0, "file_triggers_enabled": 0, "queue_all_runs": 0,
+			"global_remote_state": 0, "allow_destroy_plan": 1, "structured_run_output_enabled": 1,
+		})
+		convey.So(settings["app-frontend"]["auto_apply"], convey.ShouldEqual, 1)
+		convey.So(settings["network-prod"]["speculative_enabled"], convey.ShouldEqual, 1)
+
+		values := make(map[string]float64)
+		for _, m := range scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesSettingsValue) {
+			if m.labels["name"] == "app-frontend" {
+				values[m.labels["setting"]+"="+m.labels["value"]] = m.value
+			}
+		}
+		convey.So(values, convey.ShouldResemble, map[string]float64{
+			"execution_mode=remote": 1, "execution_mode=local": 0, "execution_mode=agent": 0,
+			"source=tfe-ui": 0, "source=tfe-api": 0, "source=tfe-module": 1, "source=terraform": 0,
+		})
+
+		// Unknown values are added to the known ones, unknown sources are left out.
+		var modes []string
+		for _, m := range getWorkspaceSettings(&tfe.Workspace{ExecutionMode: "hybrid"}, "", "demo-org", "") {
+			if m.Desc() == WorkspacesSettingsValue {
+				got := readMetric(m)
+				convey.So(got.labels["setting"], convey.ShouldEqual, "execution_mode")
+				if got.value == 1 {
+					modes = append(modes, got.labels["value"])
+				}
+			}
+		}
+		convey.So(modes, convey.ShouldResemble, []string{"hybrid"})
+	})
+
+	convey.Convey("Workspace sources are decoded from the workspace list", t, func() {
+		p, err := decodeWorkspacesPage([]byte(`{
+			"data": [
+				{"id": "ws-1", "type": "workspaces", "attributes": {"name": "one", "source": "tfe-api"}},
+				{"id": "ws-2", "type": "workspaces", "attributes": {"name": "two"}}
+			],
+			"meta": {"pagination": {"current-page": 1, "total-pages": 3}}
+		}`))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(p.items, convey.ShouldHaveLength, 2)
+		convey.So(p.items[1].Name, convey.ShouldEqual, "two")
+		convey.So(p.sources, convey.ShouldResemble, map[string]string{"ws-1": "tfe-api"})
+		convey.So(p.pagination.TotalPages, convey.ShouldEqual, 3)
+	})
+
+	convey.Convey("Workspace counts", t, func() {
+		labels := labelMap{"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform"}
+		convey.So(scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesResources), convey.ShouldContain, MetricResult{labels: labels, value: 142, metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesRUM), convey.ShouldContain, MetricResult{labels: labels, value: 120, metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesRuns), convey.ShouldContain, MetricResult{labels: labels, value: 310, metricType: dto.MetricType_COUNTER})
+		convey.So(scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesRunFailures), convey.ShouldContain, MetricResult{labels: labels, value: 12, metricType: dto.MetricType_COUNTER})
+	})
+
+	convey.Convey("Workspace activity", t, func() {
+		// Only network-prod had activity after this time in the demo dataset.
+		config.WorkspacesStaleAfter = time.Since(time.Date(2024, 9, 2, 9, 30, 0, 0, time.UTC))
+
+		stale := make(map[string]float64)
+		for _, m := range scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesStale) {
+			stale[m.labels["name"]] = m.value
+		}
+		convey.So(stale, convey.ShouldResemble, map[string]float64{"network-prod": 0, "network-dev": 1, "app-frontend": 1, "sandbox": 1})
+
+		lastRun := scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesLastRun)
+		convey.So(lastRun, convey.ShouldHaveLength, 3)
+		for _, m := range lastRun {
+			convey.So(m.labels["name"], convey.ShouldNotEqual, "sandbox")
+		}
+		convey.So(lastRun, convey.ShouldContain, MetricResult{labels: labelMap{"id": "ws-network-prod", "name": "network-prod", "organization": "demo-org", "project": "Platform"}, value: float64(time.Date(2024, 9, 2, 10, 0, 0, 0, time.UTC).Unix()), metricType: dto.MetricType_GAUGE})
+		convey.So(scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesLastStateChange), convey.ShouldHaveLength, 3)
+		convey.So(scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesLastUpdate), convey.ShouldHaveLength, 4)
+	})
+}
+
+func TestWorkspaceLocks(t *testing.T) {
+	config := newDemoConfig(t)
+
+	convey.Convey("Workspace locks and their holders", t, func() {
+		locked := make(map[string]MetricResult)
+		for _, m := range scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesLocked) {
+			locked[m.labels["name"]] = m
+		}
+		convey.So(locked, convey.ShouldHaveLength, 4)
+		convey.So(locked["network-dev"].value, convey.ShouldEqual, 1)
+		convey.So(locked["app-frontend"].value, convey.ShouldEqual, 1)
+		convey.So(locked["sandbox"], convey.ShouldResemble, MetricResult{labels: labelMap{
+			"id": "ws-sandbox", "name": "sandbox", "organization": "demo-org", "project": "Default Project",
+		}, value: 0, metricType: dto.MetricType_GAUGE})
+
+		since := make(map[string]MetricResult)
+		for _, m := range scrapeMetrics(t, ScrapeWorkspaces{}, config, WorkspacesLockedSince) {
+			since[m.labels["name"]] = m
+		}
+		convey.So(since, convey.ShouldHaveLength, 2)
+		convey.So(since["network-dev"].labels["locked_by_type"], convey.ShouldEqual, "user")
+		convey.So(since["app-frontend"].labels["locked_by_type"], convey.ShouldEqual, "run")
+		// Run locks date from the creation of the run.
+		convey.So(since["app-frontend"].value, convey.ShouldEqual, float64(time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC).Unix()))
+		convey.So(since["network-dev"].value, convey.ShouldAlmostEqual, float64(time.Now().Unix()), 60)
+	})
+
+	convey.Convey("Locks keep their time across scrapes while held by the same holder", t, func() {
+		now := time.Now()
+		locks := newWorkspaceLocks(map[string]workspaceLock{
+			"ws-1": {HolderType: "user", HolderID: "user-alice", Since: now.Add(-2 * time.Hour)},
+			"ws-2": {HolderType: "user", HolderID: "user-alice", Since: now.Add(-2 * time.Hour)},
+		})
+
+		held := locks.observe(&tfe.Workspace{ID: "ws-1", Locked: true, LockedBy: &tfe.LockedByChoice{User: &tfe.User{ID: "user-alice"}}}, now)
+		convey.So(held.Since, convey.ShouldEqual, now.Add(-2*time.Hour))
+		relocked := locks.observe(&tfe.Workspace{ID: "ws-2", Locked: true, LockedBy: &tfe.LockedByChoice{Team: &tfe.Team{ID: "team-owners"}}}, now)
+		convey.So(relocked.Since, convey.ShouldEqual, now)
+		convey.So(locks.observe(&tfe.Workspace{ID: "ws-3"}, now), convey.ShouldBeNil)
+		convey.So(locks.current, convey.ShouldHaveLength, 2)
+
+		m := getWorkspaceLock(&tfe.Workspace{ID: "ws-1", Name: "one"}, held, "demo-org", "")
+		convey.So(readMetric(m[1]).value, convey.ShouldEqual, float64(now.Add(-2*time.Hour).Unix()))
+	})
+
+	convey.Convey("Workspaces state round trip", t, func() {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			for range ch {
+			}
+		}()
+		state, err := ScrapeWorkspaces{}.ScrapeState(context.Background(), config, nil, ch)
+		close(ch)
+		convey.So(err, convey.ShouldBeNil)
+
+		s := &workspacesState{}
+		convey.So(json.Unmarshal(state, s), convey.ShouldBeNil)
+		convey.So(s.Locks, convey.ShouldHaveLength, 2)
+		convey.So(s.Locks["ws-network-dev"].HolderID, convey.ShouldEqual, "user-alice")
+	})
+}
@@ -0,0 +1,120 @@
+// Package fakeapi serves an in-memory Terraform Cloud/Enterprise API for tests and demos.
+//
+// The dataset is a flat list of JSON:API resources, written exactly as the real API returns
+// them. Collection endpoints such as /organizations/:name/workspaces or /workspaces/:id/runs
+// list the resources of the requested type that have a relationship to the parent resource,
+// so new endpoints usually only need new resources in the dataset.
+package fakeapi
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed demo.yaml
+var demo []byte
+
+// Dataset is the set of resources served by the fake API.
+type Dataset struct {
+	Resources []Resource `yaml:"resources" json:"resources"`
+}
+
+// Resource is a JSON:API resource object.
+type Resource struct {
+	Type          string                  `yaml:"type" json:"type"`
+	ID            string                  `yaml:"id" json:"id"`
+	Attributes    map[string]any          `yaml:"attributes,omitempty" json:"attributes,omitempty"`
+	Relationships map[string]Relationship `yaml:"relationships,omitempty" json:"relationships,omitempty"`
+}
+
+// Relationship is a JSON:API relationship object. Data holds either a single Identifier
+// or a list of them.
+type Relationship struct {
+	Data any `yaml:"data" json:"data"`
+}
+
+// Identifier is a JSON:API resource identifier object.
+type Identifier struct {
+	Type string `yaml:"type" json:"type"`
+	ID   string `yaml:"id" json:"id"`
+}
+
+// To returns a to-one relationship to the resource of the given type and id.
+func To(typ, id string) Relationship {
+	return Relationship{Data: Identifier{Type: typ, ID: id}}
+}
+
+// ToMany returns a to-many relationship to the given resources.
+func ToMany(ids ...Identifier) Relationship {
+	return Relationship{Data: ids}
+}
+
+// Identifiers returns the resources the relationship points to.
+func (r Relationship) Identifiers() []Identifier {
+	switch data := r.Data.(type) {
+	case Identifier:
+		return []Identifier{data}
+	case []Identifier:
+		return data
+	case map[string]any:
+		return []Identifier{identifierFromMap(data)}
+	case []any:
+		ids := make([]Identifier, 0, len(data))
+		for _, d := range data {
+			if m, ok := d.(map[string]any); ok {
+				ids = append(ids, identifierFromMap(m))
+			}
+		}
+		return ids
+	}
+
+	return nil
+}
+
+func (r Relationship) toOne() bool {
+	switch r.Data.(type) {
+	case Identifier, map[string]any:
+		return true
+	}
+
+	return false
+}
+
+func identifierFromMap(m map[string]any) Identifier {
+	typ, _ := m["type"].(string)
+	id, _ := m["id"].(string)
+	return Identifier{Type: typ, ID: id}
+}
+
+// Demo returns the dataset bundled with the package, used for dashboard demos.
+func Demo() (*Dataset, error) {
+	return parseDataset(demo)
+}
+
+// LoadDataset reads a dataset from a YAML (or JSON) fixture file.
+func LoadDataset(path string) (*Dataset, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDataset(b)
+}
+
+func parseDataset(b []byte) (*Dataset, error) {
+	d := &Dataset{}
+	if err := yaml.Unmarshal(b, d); err != nil {
+		return nil, fmt.Errorf("parsing dataset: %v", err)
+	}
+
+	for i, r := range d.Resources {
+		if r.Type == "" || r.ID == "" {
+			return nil, fmt.Errorf("parsing dataset: resource %d is missing its type or id", i)
+		}
+	}
+
+	return d, nil
+}
@@ -0,0 +1,388 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	basePath        = "/api/v2/"
+	defaultPageSize = 20
+	contentType     = "application/vnd.api+json"
+)
+
+// toOneEndpoints lists the relationship endpoints that return a single resource, and
+// respond with 404 when the relationship isn't set.
+var toOneEndpoints = map[string]bool{
+	"current-assessment-result": true,
+	"current-state-version":     true,
+	"current-run":               true,
+}
+
+// rawEndpoints lists the endpoints that serve the attribute of the same name of their parent
+// as a plain JSON document, like /plans/:id/json-output.
+var rawEndpoints = map[string]bool{
+	"json-output": true,
+}
+
+// Server is an http.Handler serving a Dataset with the Terraform Cloud/Enterprise API conventions.
+type Server struct {
+	dataset *Dataset
+}
+
+// NewServer returns a Server for the dataset.
+func NewServer(dataset *Dataset) *Server {
+	return &Server{dataset: dataset}
+}
+
+type document struct {
+	Data     any        `json:"data"`
+	Included []Resource `json:"included,omitempty"`
+	Meta     any        `json:"meta,omitempty"`
+}
+
+type pagination struct {
+	CurrentPage  int  `json:"current-page"`
+	PreviousPage *int `json:"prev-page"`
+	NextPage     *int `json:"next-page"`
+	TotalPages   int  `json:"total-pages"`
+	TotalCount   int  `json:"total-count"`
+	PageSize     int  `json:"page-size"`
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, basePath), "/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case path == "organization/audit-trail":
+		s.serveAuditTrail(w, r)
+	case path == "ping":
+		w.Header().Set("TFP-API-Version", "2.6")
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 1:
+		s.serveList(w, r, s.filter(segments[0], r, nil))
+	case len(segments) == 2:
+		res, ok := s.find(segments[0], segments[1])
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		s.serveOne(w, r, res)
+	case len(segments) == 3:
+		parent, ok := s.find(segments[0], segments[1])
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		if rawEndpoints[segments[2]] {
+			raw, ok := parent.Attributes[segments[2]]
+			if !ok {
+				writeError(w, http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(raw)
+			return
+		}
+		// Relationship endpoints like /workspaces/:id/current-state-version.
+		if rel, ok := parent.Relationships[segments[2]]; (ok && rel.toOne()) || toOneEndpoints[segments[2]] {
+			res, ok := s.related(rel)
+			if !ok {
+				writeError(w, http.StatusNotFound)
+				return
+			}
+			s.serveOne(w, r, res)
+			return
+		}
+		parentID := Identifier{Type: parent.Type, ID: parent.ID}
+		s.serveList(w, r, s.filter(segments[2], r, &parentID))
+	case len(segments) == 8 && segments[2] == "registry-modules" && segments[7] == "version":
+		// /organizations/:org/registry-modules/:registry/:namespace/:name/:provider/version?module_version=:version
+		module, ok := s.findByAttributes("registry-modules", map[string]string{"namespace": segments[4], "name": segments[5], "provider": segments[6]})
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		res, ok := s.findRelated("registry-module-versions", module, "version", r.URL.Query().Get("module_version"))
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		s.serveOne(w, r, res)
+	case len(segments) == 7 && segments[2] == "registry-providers" && segments[6] == "versions":
+		// /organizations/:org/registry-providers/:registry/:namespace/:name/versions
+		provider, ok := s.findByAttributes("registry-providers", map[string]string{"registry-name": segments[3], "namespace": segments[4], "name": segments[5]})
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		parentID := Identifier{Type: provider.Type, ID: provider.ID}
+		s.serveList(w, r, s.filter("registry-provider-versions", r, &parentID))
+	case len(segments) == 9 && segments[2] == "registry-providers" && segments[6] == "versions" && segments[8] == "platforms":
+		// /organizations/:org/registry-providers/:registry/:namespace/:name/versions/:version/platforms
+		provider, ok := s.findByAttributes("registry-providers", map[string]string{"registry-name": segments[3], "namespace": segments[4], "name": segments[5]})
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		version, ok := s.findRelated("registry-provider-versions", provider, "version", segments[7])
+		if !ok {
+			writeError(w, http.StatusNotFound)
+			return
+		}
+		parentID := Identifier{Type: version.Type, ID: version.ID}
+		s.serveList(w, r, s.filter("registry-provider-platforms", r, &parentID))
+	default:
+		writeError(w, http.StatusNotFound)
+	}
+}
+
+// findByAttributes returns the first resource of the given type with the given attribute values,
+// for the registry endpoints that address resources by name instead of id.
+func (s *Server) findByAttributes(typ string, attributes map[string]string) (Resource, bool) {
+	for _, res := range s.dataset.Resources {
+		if res.Type != typ {
+			continue
+		}
+		matches := true
+		for k, v := range attributes {
+			if res.Attributes[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			return res, true
+		}
+	}
+
+	return Resource{}, false
+}
+
+// findRelated returns the resource of the given type related to parent whose attribute has the given value.
+func (s *Server) findRelated(typ string, parent Resource, attribute, value string) (Resource, bool) {
+	parentID := Identifier{Type: parent.Type, ID: parent.ID}
+	for _, res := range s.dataset.Resources {
+		if res.Type == typ && res.relatesTo(parentID) && res.Attributes[attribute] == value {
+			return res, true
+		}
+	}
+
+	return Resource{}, false
+}
+
+// find returns the resource of the given type and id.
+func (s *Server) find(typ, id string) (Resource, bool) {
+	for _, res := range s.dataset.Resources {
+		if res.Type == typ && res.ID == id {
+			return res, true
+		}
+	}
+
+	return Resource{}, false
+}
+
+// related returns the first resource of a to-one relationship.
+func (s *Server) related(rel Relationship) (Resource, bool) {
+	for _, id := range rel.Identifiers() {
+		return s.find(id.Type, id.ID)
+	}
+
+	return Resource{}, false
+}
+
+// filter returns the resources of the given type that relate to parent, if not nil, and
+// match the filter[<relationship>][id] query parameters.
+func (s *Server) filter(typ string, r *http.Request, parent *Identifier) []Resource {
+	var matches []Resource
+	for _, res := range s.dataset.Resources {
+		if res.Type != typ {
+			continue
+		}
+		if parent != nil && !res.relatesTo(*parent) {
+			continue
+		}
+		if !res.matchesFilters(r) {
+			continue
+		}
+		matches = append(matches, res)
+	}
+
+	return matches
+}
+
+func (res Resource) relatesTo(target Identifier) bool {
+	for _, rel := range res.Relationships {
+		for _, id := range rel.Identifiers() {
+			if id == target {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (res Resource) matchesFilters(r *http.Request) bool {
+	for key, values := range r.URL.Query() {
+		name, ok := strings.CutPrefix(key, "filter[")
+		if !ok || !strings.HasSuffix(name, "][id]") {
+			continue
+		}
+		name = strings.ReplaceAll(strings.TrimSuffix(name, "][id]"), "_", "-")
+
+		found := false
+		for _, id := range res.Relationships[name].Identifiers() {
+			if id.ID == values[0] {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// included returns the resources referenced by the include query parameter.
+func (s *Server) included(r *http.Request, resources ...Resource) []Resource {
+	include := r.URL.Query().Get("include")
+	if include == "" {
+		return nil
+	}
+
+	seen := make(map[Identifier]bool)
+	var included []Resource
+	for _, name := range strings.Split(include, ",") {
+		// Nested includes like configuration_version.ingress_attributes only include the first level.
+		name, _, _ = strings.Cut(name, ".")
+		name = strings.ReplaceAll(name, "_", "-")
+		for _, res := range resources {
+			for _, id := range res.Relationships[name].Identifiers() {
+				if seen[id] {
+					continue
+				}
+				if inc, ok := s.find(id.Type, id.ID); ok {
+					seen[id] = true
+					included = append(included, inc)
+				}
+			}
+		}
+	}
+
+	return included
+}
+
+func (s *Server) serveOne(w http.ResponseWriter, r *http.Request, res Resource) {
+	writeDocument(w, document{
+		Data:     res,
+		Included: s.included(r, res),
+	})
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, resources []Resource) {
+	page, size := pageParam(r, "page[number]", 1), pageParam(r, "page[size]", defaultPageSize)
+
+	p := pagination{
+		CurrentPage: page,
+		TotalCount:  len(resources),
+		TotalPages:  (len(resources) + size - 1) / size,
+		PageSize:    size,
+	}
+	if p.TotalPages == 0 {
+		p.TotalPages = 1
+	}
+	if page > 1 {
+		prev := page - 1
+		p.PreviousPage = &prev
+	}
+	if page < p.TotalPages {
+		next := page + 1
+		p.NextPage = &next
+	}
+
+	start := min((page-1)*size, len(resources))
+	end := min(start+size, len(resources))
+	items := resources[start:end]
+	if items == nil {
+		items = []Resource{}
+	}
+
+	writeDocument(w, document{
+		Data:     items,
+		Included: s.included(r, items...),
+		Meta:     map[string]any{"pagination": p},
+	})
+}
+
+// serveAuditTrail serves the audit-trails resources as the audit trail of the organization of the token,
+// which isn't a JSON:API document: every event is the id of the resource merged with its attributes.
+func (s *Server) serveAuditTrail(w http.ResponseWriter, r *http.Request) {
+	since, _ := time.Parse(time.RFC3339, r.URL.Query().Get("since"))
+
+	var events []map[string]any
+	for _, res := range s.dataset.Resources {
+		if res.Type != "audit-trails" {
+			continue
+		}
+		timestamp, _ := res.Attributes["timestamp"].(string)
+		if t, err := time.Parse(time.RFC3339, timestamp); err == nil && t.Before(since) {
+			continue
+		}
+		event := map[string]any{"id": res.ID}
+		for k, v := range res.Attributes {
+			event[k] = v
+		}
+		events = append(events, event)
+	}
+
+	page, size := pageParam(r, "page[number]", 1), pageParam(r, "page[size]", defaultPageSize)
+	totalPages := max((len(events)+size-1)/size, 1)
+	start := min((page-1)*size, len(events))
+	end := min(start+size, len(events))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": events[start:end],
+		"pagination": map[string]int{
+			"current_page": page,
+			"total_pages":  totalPages,
+			"total_count":  len(events),
+		},
+	})
+}
+
+func pageParam(r *http.Request, key string, def int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil || v < 1 {
+		return def
+	}
+
+	return v
+}
+
+func writeDocument(w http.ResponseWriter, doc document) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(doc)
+}
+
+func writeError(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"status": strconv.Itoa(status), "title": http.StatusText(status)}},
+	})
+}
@@ -0,0 +1,95 @@
+package fakeapi
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func newClient(t *testing.T, dataset *Dataset) *tfe.Client {
+	t.Helper()
+	server := httptest.NewServer(NewServer(dataset))
+	t.Cleanup(server.Close)
+
+	client, err := tfe.NewClient(&tfe.Config{Address: server.URL, Token: "test"})
+	if err != nil {
+		t.Fatalf("error creating a stub api client: %s", err)
+	}
+	return client
+}
+
+func TestDemoDataset(t *testing.T) {
+	dataset, err := Demo()
+	if err != nil {
+		t.Fatalf("error loading demo dataset: %s", err)
+	}
+	client := newClient(t, dataset)
+	ctx := context.Background()
+
+	convey.Convey("Organizations are listed and read", t, func() {
+		oo, err := client.Organizations.List(ctx, nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(oo.Items, convey.ShouldHaveLength, 1)
+
+		o, err := client.Organizations.Read(ctx, "demo-org")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(o.Email, convey.ShouldEqual, "platform@example.com")
+	})
+
+	convey.Convey("Workspaces of an organization include their relations", t, func() {
+		wl, err := client.Workspaces.List(ctx, "demo-org", &tfe.WorkspaceListOptions{
+			Include: []tfe.WSIncludeOpt{"project", "current_run", "current_state_version"},
+		})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(wl.Items, convey.ShouldHaveLength, 4)
+		convey.So(wl.Items[0].Project.Name, convey.ShouldEqual, "Platform")
+		convey.So(wl.Items[0].CurrentRun.Status, convey.ShouldEqual, tfe.RunApplied)
+		convey.So(*wl.Items[0].CurrentStateVersion.BillableRUMCount, convey.ShouldEqual, 120)
+	})
+
+	convey.Convey("Runs of a workspace are listed", t, func() {
+		rl, err := client.Runs.List(ctx, "ws-network-prod", nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(rl.Items, convey.ShouldHaveLength, 2)
+	})
+
+	convey.Convey("Plan JSON outputs are served as plain JSON", t, func() {
+		b, err := client.Plans.ReadJSONOutput(ctx, "plan-network-2")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(string(b), convey.ShouldContainSubstring, `"source":"app.terraform.io/demo-org/vpc/aws"`)
+	})
+
+	convey.Convey("Unknown resources are not found", t, func() {
+		_, err := client.Organizations.Read(ctx, "unknown")
+		convey.So(err, convey.ShouldEqual, tfe.ErrResourceNotFound)
+	})
+}
+
+func TestPagination(t *testing.T) {
+	dataset := &Dataset{Resources: []Resource{{Type: "organizations", ID: "org"}}}
+	for i := 0; i < 5; i++ {
+		dataset.Resources = append(dataset.Resources, Resource{
+			Type:          "projects",
+			ID:            fmt.Sprintf("prj-%d", i),
+			Attributes:    map[string]any{"name": fmt.Sprintf("project-%d", i)},
+			Relationships: map[string]Relationship{"organization": To("organizations", "org")},
+		})
+	}
+	client := newClient(t, dataset)
+
+	convey.Convey("Lists are paginated", t, func() {
+		pl, err := client.Projects.List(context.Background(), "org", &tfe.ProjectListOptions{
+			ListOptions: tfe.ListOptions{PageNumber: 2, PageSize: 2},
+		})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(pl.Pagination.TotalPages, convey.ShouldEqual, 3)
+		convey.So(pl.Pagination.TotalCount, convey.ShouldEqual, 5)
+		convey.So(pl.Items, convey.ShouldHaveLength, 2)
+		convey.So(pl.Items[0].Name, convey.ShouldEqual, "project-2")
+	})
+}
@@ -0,0 +1,118 @@
+package setup
+
+import "time"
+
+// Collectors holds the per-scraper settings. Every scraper can be turned off with
+// --no-collector.<name> and refreshed less often than the rest with --collector.<name>.interval.
+type Collectors struct {
+	Organizations                    bool          `name:"collector.organizations" env:"TF_COLLECTOR_ORGANIZATIONS" default:"true" negatable:"" help:"Enable the organizations collector."`
+	OrganizationsInterval            time.Duration `name:"collector.organizations.interval" env:"TF_COLLECTOR_ORGANIZATIONS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the organizations collector (0 refreshes on every scrape)."`
+	Workspaces                       bool          `name:"collector.workspaces" env:"TF_COLLECTOR_WORKSPACES" default:"true" negatable:"" help:"Enable the workspaces collector."`
+	WorkspacesInterval               time.Duration `name:"collector.workspaces.interval" env:"TF_COLLECTOR_WORKSPACES_INTERVAL" default:"0s" help:"Minimum time between refreshes of the workspaces collector (0 refreshes on every scrape)."`
+	WorkspacesStaleAfter             time.Duration `name:"collector.workspaces.stale-after" env:"TF_COLLECTOR_WORKSPACES_STALE_AFTER" default:"2160h" help:"Workspaces without a run, state change or update for this long are reported as stale (0 disables the detection)."`
+	Teams                            bool          `name:"collector.teams" env:"TF_COLLECTOR_TEAMS" default:"true" negatable:"" help:"Enable the teams collector."`
+	TeamsInterval                    time.Duration `name:"collector.teams.interval" env:"TF_COLLECTOR_TEAMS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the teams collector (0 refreshes on every scrape)."`
+	Projects                         bool          `name:"collector.projects" env:"TF_COLLECTOR_PROJECTS" default:"true" negatable:"" help:"Enable the projects collector."`
+	ProjectsInterval                 time.Duration `name:"collector.projects.interval" env:"TF_COLLECTOR_PROJECTS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the projects collector (0 refreshes on every scrape)."`
+	PolicySets                       bool          `name:"collector.policysets" env:"TF_COLLECTOR_POLICYSETS" default:"true" negatable:"" help:"Enable the policysets collector."`
+	PolicySetsInterval               time.Duration `name:"collector.policysets.interval" env:"TF_COLLECTOR_POLICYSETS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the policysets collector (0 refreshes on every scrape)."`
+	RegistryModules                  bool          `name:"collector.registrymodules" env:"TF_COLLECTOR_REGISTRYMODULES" default:"true" negatable:"" help:"Enable the registrymodules collector."`
+	RegistryModulesInterval          time.Duration `name:"collector.registrymodules.interval" env:"TF_COLLECTOR_REGISTRYMODULES_INTERVAL" default:"0s" help:"Minimum time between refreshes of the registrymodules collector (0 refreshes on every scrape)."`
+	RegistryModulesConsumers         bool          `name:"collector.registrymodules.consumers" env:"TF_COLLECTOR_REGISTRYMODULES_CONSUMERS" default:"false" negatable:"" help:"Count the workspaces calling every registry module, which reads the current state version and plan of every workspace."`
+	RegistryModulesHostname          string        `name:"collector.registrymodules.hostname" env:"TF_COLLECTOR_REGISTRYMODULES_HOSTNAME" default:"" help:"Hostname of the private registry in module sources, such as app.terraform.io (defaults to the host of the API address)."`
+	RegistryProviders                bool          `name:"collector.registryproviders" env:"TF_COLLECTOR_REGISTRYPROVIDERS" default:"true" negatable:"" help:"Enable the registryproviders collector."`
+	RegistryProvidersInterval        time.Duration `name:"collector.registryproviders.interval" env:"TF_COLLECTOR_REGISTRYPROVIDERS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the registryproviders collector (0 refreshes on every scrape)."`
+	Runs                             bool          `name:"collector.runs" env:"TF_COLLECTOR_RUNS" default:"false" negatable:"" help:"Enable the runs collector (one request per workspace)."`
+	RunsInterval                     time.Duration `name:"collector.runs.interval" env:"TF_COLLECTOR_RUNS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the runs collector (0 refreshes on every scrape)."`
+	RunsLookback                     time.Duration `name:"collector.runs.lookback" env:"TF_COLLECTOR_RUNS_LOOKBACK" default:"24h" help:"Only runs created within this window are counted by the runs collector."`
+	Assessments                      bool          `name:"collector.assessments" env:"TF_COLLECTOR_ASSESSMENTS" default:"true" negatable:"" help:"Enable the assessments collector (one request per workspace with health assessments)."`
+	AssessmentsInterval              time.Duration `name:"collector.assessments.interval" env:"TF_COLLECTOR_ASSESSMENTS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the assessments collector (0 refreshes on every scrape)."`
+	Memberships                      bool          `name:"collector.memberships" env:"TF_COLLECTOR_MEMBERSHIPS" default:"true" negatable:"" help:"Enable the memberships collector."`
+	MembershipsInterval              time.Duration `name:"collector.memberships.interval" env:"TF_COLLECTOR_MEMBERSHIPS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the memberships collector (0 refreshes on every scrape)."`
+	TeamWorkspaces                   bool          `name:"collector.teamworkspaces" env:"TF_COLLECTOR_TEAMWORKSPACES" default:"false" negatable:"" help:"Enable the team workspace access collector (one request per workspace)."`
+	TeamWorkspacesInterval           time.Duration `name:"collector.teamworkspaces.interval" env:"TF_COLLECTOR_TEAMWORKSPACES_INTERVAL" default:"0s" help:"Minimum time between refreshes of the team workspace access collector (0 refreshes on every scrape)."`
+	TeamProjects                     bool          `name:"collector.teamprojects" env:"TF_COLLECTOR_TEAMPROJECTS" default:"true" negatable:"" help:"Enable the team project access collector (one request per project)."`
+	TeamProjectsInterval             time.Duration `name:"collector.teamprojects.interval" env:"TF_COLLECTOR_TEAMPROJECTS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the team project access collector (0 refreshes on every scrape)."`
+	AgentPools                       bool          `name:"collector.agentpools" env:"TF_COLLECTOR_AGENTPOOLS" default:"true" negatable:"" help:"Enable the agentpools collector."`
+	AgentPoolsInterval               time.Duration `name:"collector.agentpools.interval" env:"TF_COLLECTOR_AGENTPOOLS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the agentpools collector (0 refreshes on every scrape)."`
+	Variables                        bool          `name:"collector.variables" env:"TF_COLLECTOR_VARIABLES" default:"false" negatable:"" help:"Enable the variables collector (one request per workspace)."`
+	VariablesInterval                time.Duration `name:"collector.variables.interval" env:"TF_COLLECTOR_VARIABLES_INTERVAL" default:"0s" help:"Minimum time between refreshes of the variables collector (0 refreshes on every scrape)."`
+	RunTasks                         bool          `name:"collector.runtasks" env:"TF_COLLECTOR_RUNTASKS" default:"false" negatable:"" help:"Enable the runtasks collector (one request per recent run of workspaces with run tasks, within the runs lookback window)."`
+	RunTasksInterval                 time.Duration `name:"collector.runtasks.interval" env:"TF_COLLECTOR_RUNTASKS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the runtasks collector (0 refreshes on every scrape)."`
+	PolicyEvaluations                bool          `name:"collector.policyevaluations" env:"TF_COLLECTOR_POLICYEVALUATIONS" default:"false" negatable:"" help:"Enable the policyevaluations collector (several requests per recent run, within the runs lookback window)."`
+	PolicyEvaluationsInterval        time.Duration `name:"collector.policyevaluations.interval" env:"TF_COLLECTOR_POLICYEVALUATIONS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the policyevaluations collector (0 refreshes on every scrape)."`
+	Policies                         bool          `name:"collector.policies" env:"TF_COLLECTOR_POLICIES" default:"true" negatable:"" help:"Enable the policies collector."`
+	PoliciesInterval                 time.Duration `name:"collector.policies.interval" env:"TF_COLLECTOR_POLICIES_INTERVAL" default:"0s" help:"Minimum time between refreshes of the policies collector (0 refreshes on every scrape)."`
+	Governance                       bool          `name:"collector.governance" env:"TF_COLLECTOR_GOVERNANCE" default:"true" negatable:"" help:"Enable the governance collector."`
+	GovernanceInterval               time.Duration `name:"collector.governance.interval" env:"TF_COLLECTOR_GOVERNANCE_INTERVAL" default:"0s" help:"Minimum time between refreshes of the governance collector (0 refreshes on every scrape)."`
+	GovernanceInactivity             time.Duration `name:"collector.governance.inactivity" env:"TF_COLLECTOR_GOVERNANCE_INACTIVITY" default:"720h" help:"Workspaces without a run for this long, or without any run, are reported with the inactive gap."`
+	TerraformVersions                bool          `name:"collector.terraformversions" env:"TF_COLLECTOR_TERRAFORMVERSIONS" default:"true" negatable:"" help:"Enable the Terraform versions collector."`
+	TerraformVersionsInterval        time.Duration `name:"collector.terraformversions.interval" env:"TF_COLLECTOR_TERRAFORMVERSIONS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the Terraform versions collector (0 refreshes on every scrape)."`
+	TerraformVersionsFile            string        `name:"collector.terraformversions.file" env:"TF_COLLECTOR_TERRAFORMVERSIONS_FILE" default:"" help:"JSON file listing the available Terraform versions, used instead of the admin API. The bundled list is used when neither is available."`
+	TerraformVersionsSupportedMinors int           `name:"collector.terraformversions.supported-minors" env:"TF_COLLECTOR_TERRAFORMVERSIONS_SUPPORTED_MINORS" default:"3" help:"Workspaces this many minor versions behind the latest Terraform version are reported as unsupported (0 only reports deprecated versions)."`
+	StateVersions                    bool          `name:"collector.stateversions" env:"TF_COLLECTOR_STATEVERSIONS" default:"false" negatable:"" help:"Enable the state versions collector, which reads the current state version and plan of every workspace."`
+	StateVersionsInterval            time.Duration `name:"collector.stateversions.interval" env:"TF_COLLECTOR_STATEVERSIONS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the state versions collector (0 refreshes on every scrape)."`
+	Notifications                    bool          `name:"collector.notifications" env:"TF_COLLECTOR_NOTIFICATIONS" default:"false" negatable:"" help:"Enable the notifications collector (one request per workspace)."`
+	NotificationsInterval            time.Duration `name:"collector.notifications.interval" env:"TF_COLLECTOR_NOTIFICATIONS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the notifications collector (0 refreshes on every scrape)."`
+	VCS                              bool          `name:"collector.vcs" env:"TF_COLLECTOR_VCS" default:"true" negatable:"" help:"Enable the VCS providers collector."`
+	VCSInterval                      time.Duration `name:"collector.vcs.interval" env:"TF_COLLECTOR_VCS_INTERVAL" default:"0s" help:"Minimum time between refreshes of the VCS providers collector (0 refreshes on every scrape)."`
+	AuditTrail                       bool          `name:"collector.audittrail" env:"TF_COLLECTOR_AUDITTRAIL" default:"false" negatable:"" help:"Enable the audit trail collector, only available on HCP Terraform."`
+	AuditTrailInterval               time.Duration `name:"collector.audittrail.interval" env:"TF_COLLECTOR_AUDITTRAIL_INTERVAL" default:"0s" help:"Minimum time between refreshes of the audit trail collector (0 refreshes on every scrape)."`
+	AuditTrailToken                  string        `name:"collector.audittrail.token" env:"TF_COLLECTOR_AUDITTRAIL_TOKEN" help:"Organization token for reading the audit trail, which requires one (empty uses the API token)."`
+	AuditTrailLookback               time.Duration `name:"collector.audittrail.lookback" env:"TF_COLLECTOR_AUDITTRAIL_LOOKBACK" default:"24h" help:"How far back the audit trail is read when no cursor was persisted."`
+}
+
+// Collector returns whether the named collector is enabled and the minimum time between its refreshes.
+// Unknown collectors are enabled and refreshed on every scrape.
+func (c Collectors) Collector(name string) (enabled bool, interval time.Duration) {
+	switch name {
+	case "organizations":
+		return c.Organizations, c.OrganizationsInterval
+	case "workspaces":
+		return c.Workspaces, c.WorkspacesInterval
+	case "teams":
+		return c.Teams, c.TeamsInterval
+	case "projects":
+		return c.Projects, c.ProjectsInterval
+	case "policysets":
+		return c.PolicySets, c.PolicySetsInterval
+	case "registrymodules":
+		return c.RegistryModules, c.RegistryModulesInterval
+	case "registryproviders":
+		return c.RegistryProviders, c.RegistryProvidersInterval
+	case "runs":
+		return c.Runs, c.RunsInterval
+	case "assessments":
+		return c.Assessments, c.AssessmentsInterval
+	case "memberships":
+		return c.Memberships, c.MembershipsInterval
+	case "teamworkspaces":
+		return c.TeamWorkspaces, c.TeamWorkspacesInterval
+	case "teamprojects":
+		return c.TeamProjects, c.TeamProjectsInterval
+	case "agentpools":
+		return c.AgentPools, c.AgentPoolsInterval
+	case "variables":
+		return c.Variables, c.VariablesInterval
+	case "runtasks":
+		return c.RunTasks, c.RunTasksInterval
+	case "policyevaluations":
+		return c.PolicyEvaluations, c.PolicyEvaluationsInterval
+	case "policies":
+		return c.Policies, c.PoliciesInterval
+	case "governance":
+		return c.Governance, c.GovernanceInterval
+	case "terraformversions":
+		return c.TerraformVersions, c.TerraformVersionsInterval
+	case "stateversions":
+		return c.StateVersions, c.StateVersionsInterval
+	case "notifications":
+		return c.Notifications, c.NotificationsInterval
+	case "vcs":
+		return c.VCS, c.VCSInterval
+	case "audittrail":
+		return c.AuditTrail, c.AuditTrailInterval
+	default:
+		return true, 0
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"crypto/tls"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -16,20 +18,33 @@ import (
 )
 
 type CLI struct {
-	Organizations         []string `short:"o" env:"TF_ORGANIZATIONS" placeholder:"ORG1,ORG2" help:"List of the Organization names to scrape from (Ommit to scrape all)."`
-	APIToken              string   `short:"t" env:"TF_API_TOKEN" help:"User token for autheticating with the API."`
-	APITokenFile          *os.File `placeholder:"/path/to/file" help:"File containing user token for autheticating with the API."`
-	APIAddress            string   `placeholder:"https://app.terraform.io/" help:"Terraform API address to scrape metrics from."`
-	APIInsecureSkipVerify bool     `help:"Accept any certificate presented by the API."`
-	ListenAddress         string   `default:"0.0.0.0:9100" help:"Address to listen on for web interface and telemetry."`
-	LogLevel              string   `default:"info" enum:"debug,info,warn,error" help:"Only log messages with the given severity or above. One of: [${enum}]"`
-	LogFormat             string   `default:"logfmt" enum:"logfmt,json" help:"Output format of log messages. One of: [${enum}]"`
+	Organizations                []string      `short:"o" env:"TF_ORGANIZATIONS" placeholder:"ORG1,ORG2" help:"List of the Organization names to scrape from (Ommit to scrape all)."`
+	OrganizationsInclude         string        `env:"TF_ORGANIZATIONS_INCLUDE" placeholder:"REGEX" help:"Only scrape discovered organizations whose name fully matches this regular expression."`
+	OrganizationsExclude         string        `env:"TF_ORGANIZATIONS_EXCLUDE" placeholder:"REGEX" help:"Skip discovered organizations whose name fully matches this regular expression."`
+	OrganizationsRefreshInterval time.Duration `env:"TF_ORGANIZATIONS_REFRESH_INTERVAL" default:"1h" help:"How often discovered organizations are listed again from the API."`
+	APIToken                     string        `short:"t" env:"TF_API_TOKEN" help:"User token for autheticating with the API."`
+	APITokenFile                 *os.File      `placeholder:"/path/to/file" help:"File containing user token for autheticating with the API."`
+	APIAddress                   string        `placeholder:"https://app.terraform.io/" help:"Terraform API address to scrape metrics from."`
+	APIInsecureSkipVerify        bool          `help:"Accept any certificate presented by the API."`
+	ListenAddress                string        `default:"0.0.0.0:9100" help:"Address to listen on for web interface and telemetry."`
+	ScrapeInterval               time.Duration `env:"TF_SCRAPE_INTERVAL" default:"0s" help:"Scrape the API in the background at this interval and serve the last snapshot on /metrics (0 scrapes on every request)."`
+	StateFile                    string        `env:"TF_STATE_FILE" placeholder:"/path/to/file" help:"File the state of incremental collectors, such as the audit trail cursor and counters, is persisted to across restarts (empty keeps it in memory)."`
+	LogLevel                     string        `default:"info" enum:"debug,info,warn,error" help:"Only log messages with the given severity or above. One of: [${enum}]"`
+	LogFormat                    string        `default:"logfmt" enum:"logfmt,json" help:"Output format of log messages. One of: [${enum}]"`
+
+	Collectors `embed:""`
 }
 
 type Config struct {
 	CLI
 	Client tfe.Client
 	Logger log.Logger
+	// AuditTrailClient authenticates with the organization token the audit trail requires, nil to use Client.
+	AuditTrailClient *tfe.Client
+
+	// Compiled OrganizationsInclude and OrganizationsExclude patterns.
+	includeOrganizations *regexp.Regexp
+	excludeOrganizations *regexp.Regexp
 }
 
 // NewConfig returns a new Config object that was initialized according to the CLI params.
@@ -38,9 +53,43 @@ func NewConfig() Config {
 	kong.Parse(&config.CLI)
 	config.setupLogger()
 	config.setupClient()
+	config.setupOrganizationFilters()
 	return config
 }
 
+// MatchOrganization reports whether a discovered organization should be scraped.
+func (c *Config) MatchOrganization(name string) bool {
+	if c.includeOrganizations != nil && !c.includeOrganizations.MatchString(name) {
+		return false
+	}
+
+	return c.excludeOrganizations == nil || !c.excludeOrganizations.MatchString(name)
+}
+
+func (c *Config) setupOrganizationFilters() {
+	if err := c.compileOrganizationFilters(); err != nil {
+		level.Error(c.Logger).Log("msg", "Error parsing organizations pattern", "err", err)
+		os.Exit(1)
+	}
+}
+
+func (c *Config) compileOrganizationFilters() error {
+	var err error
+	if c.OrganizationsInclude != "" {
+		if c.includeOrganizations, err = regexp.Compile("^(?:" + c.OrganizationsInclude + ")$"); err != nil {
+			return err
+		}
+	}
+
+	if c.OrganizationsExclude != "" {
+		if c.excludeOrganizations, err = regexp.Compile("^(?:" + c.OrganizationsExclude + ")$"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *Config) setupLogger() {
 	timestampFormat := log.TimestampFormat(
 		func() time.Time { return time.Now().UTC() },
@@ -102,4 +151,29 @@ func (c *Config) setupClient() {
 		os.Exit(1)
 	}
 	c.Client = *client
+
+	if c.RegistryModulesHostname == "" {
+		c.RegistryModulesHostname = registryHostname(config.Address)
+	}
+
+	if c.AuditTrailToken != "" {
+		config.Token = c.AuditTrailToken
+		if c.AuditTrailClient, err = tfe.NewClient(config); err != nil {
+			level.Error(c.Logger).Log("msg", "Error creating tfe audit trail client", "err", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// registryHostname returns the hostname private registry modules are addressed with, that of the API address.
+func registryHostname(address string) string {
+	if address == "" {
+		address = tfe.DefaultConfig().Address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
 }
@@ -0,0 +1,35 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestMatchOrganization(t *testing.T) {
+	convey.Convey("All organizations match without patterns", t, func() {
+		c := &Config{}
+		convey.So(c.compileOrganizationFilters(), convey.ShouldBeNil)
+		convey.So(c.MatchOrganization("anything"), convey.ShouldBeTrue)
+	})
+
+	convey.Convey("Patterns must match the whole name", t, func() {
+		c := &Config{CLI: CLI{OrganizationsInclude: "prod-.*", OrganizationsExclude: ".*-sandbox"}}
+		convey.So(c.compileOrganizationFilters(), convey.ShouldBeNil)
+		convey.So(c.MatchOrganization("prod-network"), convey.ShouldBeTrue)
+		convey.So(c.MatchOrganization("prod-sandbox"), convey.ShouldBeFalse)
+		convey.So(c.MatchOrganization("preprod-network"), convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Invalid patterns are rejected", t, func() {
+		c := &Config{CLI: CLI{OrganizationsInclude: "("}}
+		convey.So(c.compileOrganizationFilters(), convey.ShouldNotBeNil)
+	})
+}
+
+func TestRegistryHostname(t *testing.T) {
+	convey.Convey("Private registry hostname of the API address", t, func() {
+		convey.So(registryHostname("https://tfe.example.com:8443/"), convey.ShouldEqual, "tfe.example.com")
+		convey.So(registryHostname("https://app.terraform.io"), convey.ShouldEqual, "app.terraform.io")
+	})
+}
@@ -25,8 +25,24 @@ var (
 	BuildDate string
 )
 
-func newHandler(metrics collector.Metrics, config setup.Config) http.HandlerFunc {
+func newHandler(exporter *collector.Exporter, config setup.Config) http.HandlerFunc {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter)
+
+	gatherers := prometheus.Gatherers{
+		prometheus.DefaultGatherer,
+		registry,
+	}
+	// Delegate http serving to Prometheus client library, which will call collector.Collect.
+	h := promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+
 	return func(w http.ResponseWriter, r *http.Request) {
+		// With a background scrape interval the snapshot is refreshed elsewhere, serve it as is.
+		if config.ScrapeInterval > 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+
 		// Use request context for cancellation when connection gets closed.
 		ctx := r.Context()
 		// If a timeout is configured via the Prometheus header, add it to the context.
@@ -36,22 +52,13 @@ func newHandler(metrics collector.Metrics, config setup.Config) http.HandlerFunc
 				level.Error(config.Logger).Log("msg", "Failed to parse timeout from Prometheus header", "err", err)
 			} else {
 				// Create new timeout context with request context as parent.
-				ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
 				defer cancel()
-				// Overwrite request with timeout context.
-				r = r.WithContext(ctx)
 			}
 		}
 
-		registry := prometheus.NewRegistry()
-		registry.MustRegister(collector.New(ctx, config, metrics))
-
-		gatherers := prometheus.Gatherers{
-			prometheus.DefaultGatherer,
-			registry,
-		}
-		// Delegate http serving to Prometheus client library, which will call collector.Collect.
-		h := promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+		exporter.Refresh(ctx)
 		h.ServeHTTP(w, r)
 	}
 }
@@ -61,7 +68,13 @@ func main() {
 	level.Info(config.Logger).Log("msg", "Starting tf_exporter", "version", Version)
 	level.Debug(config.Logger).Log("msg", "Build Context", "go", GoVersion, "date", BuildDate)
 
-	handlerFunc := newHandler(collector.NewMetrics(), config)
+	exporter := collector.New(config, collector.NewMetrics())
+	if config.ScrapeInterval > 0 {
+		level.Info(config.Logger).Log("msg", "Scraping in the background", "interval", config.ScrapeInterval)
+		go exporter.Run(context.Background(), config.ScrapeInterval)
+	}
+
+	handlerFunc := newHandler(exporter, config)
 	http.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handlerFunc))
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>